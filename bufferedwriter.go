@@ -0,0 +1,127 @@
+package golearn
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// DefaultChunkSize is the chunk size NewBufferedWriterCloser uses
+// when it isn't given one, the same as bufio's default buffer
+const DefaultChunkSize = 4096
+
+// BufferedWriterCloser has a buffer and writes/closes
+// it holds on to what it is given and passes it to the writer underneath
+// a chunk at a time, so lots of little writes become fewer, bigger ones
+// whatever is left over goes out on Flush or Close
+// a BufferedWriterCloser is safe to use from many goroutines at once
+type BufferedWriterCloser struct {
+	mu     sync.Mutex
+	buffer bytes.Buffer
+	out    io.Writer
+	chunk  int
+	closed bool
+}
+
+// NewBufferedWriterCloser makes a new buffered writercloser that passes
+// chunkSize byte chunks to w, or os.Stdout when w is nil
+// chunkSize <= 0 means DefaultChunkSize
+func NewBufferedWriterCloser(w io.Writer, chunkSize int) *BufferedWriterCloser {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return &BufferedWriterCloser{out: output(w), chunk: chunkSize}
+}
+
+// Write buffers data, passing on every chunk it fills
+// n counts the bytes of data taken into the buffer, so when passing a
+// chunk on fails Write stops there and returns n < len(data) with the error,
+// and the chunk stays buffered for the next Write, Flush or Close to retry
+func (bwc *BufferedWriterCloser) Write(data []byte) (n int, err error) {
+	bwc.mu.Lock()
+	defer bwc.mu.Unlock()
+	if bwc.closed {
+		return 0, ErrWriterClosed
+	}
+
+	for len(data) > 0 {
+		if bwc.buffer.Len() >= bwc.chunk {
+			if err := bwc.flush(); err != nil {
+				return n, err
+			}
+		}
+		take := bwc.chunk - bwc.buffer.Len()
+		if take > len(data) {
+			take = len(data)
+		}
+		bwc.buffer.Write(data[:take])
+		data = data[take:]
+		n += take
+	}
+	if bwc.buffer.Len() >= bwc.chunk {
+		// a full chunk goes straight out, but data is already ours,
+		// so a failure here is left for the next call to report
+		bwc.flush()
+	}
+	return n, nil
+}
+
+// Flush passes on everything still buffered, full chunk or not
+func (bwc *BufferedWriterCloser) Flush() error {
+	bwc.mu.Lock()
+	defer bwc.mu.Unlock()
+	if bwc.closed {
+		return ErrWriterClosed
+	}
+	return bwc.flushAll()
+}
+
+// Close flushes what is left and stops taking writes
+// it doesn't close the writer underneath, that belongs to whoever made it
+// closing twice does nothing
+func (bwc *BufferedWriterCloser) Close() error {
+	bwc.mu.Lock()
+	defer bwc.mu.Unlock()
+	if bwc.closed {
+		return nil
+	}
+	if err := bwc.flushAll(); err != nil {
+		return err
+	}
+	bwc.closed = true
+	return nil
+}
+
+// Buffered is how many bytes are waiting to be passed on
+func (bwc *BufferedWriterCloser) Buffered() int {
+	bwc.mu.Lock()
+	defer bwc.mu.Unlock()
+	return bwc.buffer.Len()
+}
+
+// flush passes on one chunk, or less if that is all there is,
+// and keeps whatever the writer underneath didn't take, bwc.mu must be held
+func (bwc *BufferedWriterCloser) flush() error {
+	chunk := bwc.buffer.Bytes()
+	if len(chunk) > bwc.chunk {
+		chunk = chunk[:bwc.chunk]
+	}
+	n, err := bwc.out.Write(chunk)
+	if n > len(chunk) {
+		n = len(chunk) // a writer claiming more than it was given
+	}
+	bwc.buffer.Next(n)
+	if err == nil && n < len(chunk) {
+		err = io.ErrShortWrite
+	}
+	return err
+}
+
+func (bwc *BufferedWriterCloser) flushAll() error {
+	for bwc.buffer.Len() > 0 {
+		if err := bwc.flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package golearn
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// chunkRecorder remembers every write it gets
+type chunkRecorder struct {
+	mu     sync.Mutex
+	chunks []string
+}
+
+func (c *chunkRecorder) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.chunks = append(c.chunks, string(p))
+	return len(p), nil
+}
+
+// flakyWriter fails its first fails writes, and takes at most max bytes of the rest
+type flakyWriter struct {
+	fails int
+	max   int
+	out   bytes.Buffer
+}
+
+func (f *flakyWriter) Write(p []byte) (int, error) {
+	if f.fails > 0 {
+		f.fails--
+		return 0, errors.New("try again")
+	}
+	if f.max > 0 && len(p) > f.max {
+		p = p[:f.max]
+	}
+	return f.out.Write(p)
+}
+
+func TestBufferedWriterCloserChunks(t *testing.T) {
+	var rec chunkRecorder
+	bwc := NewBufferedWriterCloser(&rec, 4)
+	for _, s := range []string{"ab", "cdefghij", "k"} {
+		if n, err := bwc.Write([]byte(s)); n != len(s) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v, want %d, nil", s, n, err, len(s))
+		}
+	}
+	if got := strings.Join(rec.chunks, "|"); got != "abcd|efgh" {
+		t.Errorf("chunks before Flush = %q, want %q", got, "abcd|efgh")
+	}
+	if bwc.Buffered() != 3 {
+		t.Errorf("Buffered() = %d, want 3", bwc.Buffered())
+	}
+	if err := bwc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(rec.chunks, "|"); got != "abcd|efgh|ijk" {
+		t.Errorf("chunks after Flush = %q, want %q", got, "abcd|efgh|ijk")
+	}
+
+	bwc.Write([]byte("lm"))
+	if err := bwc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(rec.chunks, "|"); got != "abcd|efgh|ijk|lm" {
+		t.Errorf("chunks after Close = %q, want %q", got, "abcd|efgh|ijk|lm")
+	}
+	if err := bwc.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	if n, err := bwc.Write([]byte("too late")); n != 0 || !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Write() after Close = %d, %v, want 0, %v", n, err, ErrWriterClosed)
+	}
+	if err := bwc.Flush(); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Flush() after Close error = %v, want %v", err, ErrWriterClosed)
+	}
+}
+
+func TestBufferedWriterCloserErrors(t *testing.T) {
+	// the first chunk fails to go out, so Write takes the 4 bytes that
+	// fit in the buffer and no more, then a Flush retries
+	fw := &flakyWriter{fails: 2}
+	bwc := NewBufferedWriterCloser(fw, 4)
+	if n, err := bwc.Write([]byte("abcdefgh")); n != 4 || err == nil {
+		t.Errorf("Write() = %d, %v, want 4 and an error", n, err)
+	}
+	if err := bwc.Flush(); err == nil {
+		t.Error("Flush() error = nil, want the second failure")
+	}
+	if err := bwc.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if fw.out.String() != "abcd" {
+		t.Errorf("wrote %q, want %q", fw.out.String(), "abcd")
+	}
+
+	// a writer taking less than it is given is a short write,
+	// and what it didn't take stays buffered for next time
+	fw = &flakyWriter{max: 3}
+	bwc = NewBufferedWriterCloser(fw, 8)
+	if n, err := bwc.Write([]byte("abcdefghij")); n != 8 || err != io.ErrShortWrite {
+		t.Errorf("Write() = %d, %v, want 8, %v", n, err, io.ErrShortWrite)
+	}
+	fw.max = 0
+	if err := bwc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if fw.out.String() != "abcdefgh" {
+		t.Errorf("wrote %q, want %q", fw.out.String(), "abcdefgh")
+	}
+}
+
+func TestBufferedWriterCloserConcurrent(t *testing.T) {
+	var out bytes.Buffer
+	bwc := NewBufferedWriterCloser(&out, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bwc.Write([]byte("0123456789\n"))
+		}()
+	}
+	wg.Wait()
+	bwc.Close()
+	if out.String() != strings.Repeat("0123456789\n", 100) {
+		t.Errorf("concurrent writes came out mangled:\n%s", out.String())
+	}
+}
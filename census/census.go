@@ -0,0 +1,219 @@
+// Package census is the statePopulations map from the MapsAndStructs and
+// ControlFlow lessons as real data: all 50 states with their abbreviation,
+// population and region, so examples and exercises don't need to copy
+// a map literal around
+//
+// the data is the Census Bureau's population estimates for July 1 2016
+// (see Year), kept in states.csv and embedded into the binary, so there's
+// nothing to download or open at run time
+// the District of Columbia isn't a state, so it isn't in there
+package census
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:generate go run github.com/aljo242/golearn/enumgen/cmd/enumgen -type Region -trimprefix Region
+
+// Region is one of the Census Bureau's four regions of the country
+type Region int
+
+const (
+	RegionNortheast Region = iota + 1
+	RegionMidwest
+	RegionSouth
+	RegionWest
+)
+
+// Year is when the populations were estimated
+const Year = 2016
+
+// State is one row of the dataset
+type State struct {
+	Name       string
+	Abbr       string // the two letter postal abbreviation, e.g. "CA"
+	Population int
+	Region     Region
+	Rank       int // by population, 1 is the biggest
+}
+
+//go:embed states.csv
+var statesCSV string
+
+// states is the dataset sorted by name, ranked the biggest first,
+// and the indexes into it by lowercase name and by abbreviation
+var (
+	states, ranked []State
+	byName, byAbbr map[string]int
+)
+
+func init() {
+	var err error
+	states, err = parse(strings.NewReader(statesCSV))
+	if err != nil {
+		panic(err) // states.csv is broken, the tests catch that
+	}
+
+	ranked = append([]State(nil), states...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Population > ranked[j].Population })
+	rank := make(map[string]int, len(ranked))
+	for i := range ranked {
+		ranked[i].Rank = i + 1
+		rank[ranked[i].Name] = i + 1
+	}
+
+	byName = make(map[string]int, len(states))
+	byAbbr = make(map[string]int, len(states))
+	for i := range states {
+		states[i].Rank = rank[states[i].Name]
+		byName[strings.ToLower(states[i].Name)] = i
+		byAbbr[states[i].Abbr] = i
+	}
+}
+
+// parse reads the CSV, which has a header row
+// name,abbreviation,population,region and returns its states sorted by name
+func parse(r io.Reader) ([]State, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 4
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("census: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("census: no header row")
+	}
+
+	var out []State
+	seen := make(map[string]bool)
+	for i, rec := range records[1:] {
+		line := i + 2
+		s := State{Name: rec[0], Abbr: rec[1]}
+		if s.Name == "" || len(s.Abbr) != 2 || strings.ToUpper(s.Abbr) != s.Abbr {
+			return nil, fmt.Errorf("census: line %d: bad name %q or abbreviation %q", line, s.Name, s.Abbr)
+		}
+		if seen[strings.ToLower(s.Name)] || seen[s.Abbr] {
+			return nil, fmt.Errorf("census: line %d: %s is in there twice", line, s.Name)
+		}
+		seen[strings.ToLower(s.Name)], seen[s.Abbr] = true, true
+		if s.Population, err = strconv.Atoi(rec[2]); err != nil || s.Population < 0 {
+			return nil, fmt.Errorf("census: line %d: bad population %q", line, rec[2])
+		}
+		if s.Region, err = ParseRegion(rec[3]); err != nil {
+			return nil, fmt.Errorf("census: line %d: %w", line, err)
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// States returns every state, sorted by name
+// the slice is a copy, so it's yours to sort or change
+func States() []State { return append([]State(nil), states...) }
+
+// Ranked returns every state, the biggest population first
+func Ranked() []State { return append([]State(nil), ranked...) }
+
+// Top returns the n biggest states, or all of them if n is more than 50
+func Top(n int) []State {
+	if n < 0 {
+		n = 0
+	}
+	if n > len(ranked) {
+		n = len(ranked)
+	}
+	return append([]State(nil), ranked[:n]...)
+}
+
+// ByName finds a state by its name, ignoring case
+func ByName(name string) (State, bool) {
+	i, ok := byName[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return State{}, false
+	}
+	return states[i], true
+}
+
+// ByAbbr finds a state by its postal abbreviation, ignoring case
+func ByAbbr(abbr string) (State, bool) {
+	i, ok := byAbbr[strings.ToUpper(strings.TrimSpace(abbr))]
+	if !ok {
+		return State{}, false
+	}
+	return states[i], true
+}
+
+// InRegion returns the states in region r, sorted by name
+func InRegion(r Region) []State {
+	return Filter(func(s State) bool { return s.Region == r })
+}
+
+// Filter returns the states keep returns true for, sorted by name
+func Filter(keep func(State) bool) []State {
+	var out []State
+	for _, s := range states {
+		if keep(s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Populations makes the lessons' map of state name to population
+// out of states
+func Populations(states []State) map[string]int {
+	m := make(map[string]int, len(states))
+	for _, s := range states {
+		m[s.Name] = s.Population
+	}
+	return m
+}
+
+// Stats sums up the populations of a group of states
+type Stats struct {
+	Count  int
+	Total  int
+	Mean   float64
+	Median float64 // the middle population, or the mean of the middle two
+
+	// Largest and Smallest are the most and least populous,
+	// zero when there are no states
+	Largest, Smallest State
+}
+
+// Summarize works out the Stats of states,
+// Summarize(InRegion(RegionWest)) for one region
+func Summarize(states []State) Stats {
+	st := Stats{Count: len(states)}
+	if st.Count == 0 {
+		return st
+	}
+	pops := make([]int, len(states))
+	st.Largest, st.Smallest = states[0], states[0]
+	for i, s := range states {
+		pops[i] = s.Population
+		st.Total += s.Population
+		if s.Population > st.Largest.Population {
+			st.Largest = s
+		}
+		if s.Population < st.Smallest.Population {
+			st.Smallest = s
+		}
+	}
+	st.Mean = float64(st.Total) / float64(st.Count)
+	sort.Ints(pops)
+	mid := len(pops) / 2
+	if len(pops)%2 == 1 {
+		st.Median = float64(pops[mid])
+	} else {
+		st.Median = float64(pops[mid-1]+pops[mid]) / 2
+	}
+	return st
+}
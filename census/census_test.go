@@ -0,0 +1,162 @@
+package census
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDataset(t *testing.T) {
+	all := States()
+	if len(all) != 50 {
+		t.Fatalf("%d states, want 50", len(all))
+	}
+	perRegion := map[Region]int{}
+	for i, s := range all {
+		if i > 0 && all[i-1].Name >= s.Name {
+			t.Errorf("States() isn't sorted by name at %s", s.Name)
+		}
+		if !s.Region.IsValid() || s.Population <= 0 || s.Rank < 1 || s.Rank > 50 {
+			t.Errorf("bad state %+v", s)
+		}
+		perRegion[s.Region]++
+	}
+	want := map[Region]int{RegionNortheast: 9, RegionMidwest: 12, RegionSouth: 16, RegionWest: 13}
+	if !reflect.DeepEqual(perRegion, want) {
+		t.Errorf("states per region = %v, want %v", perRegion, want)
+	}
+
+	// the numbers the lessons printed before they came from here
+	lessons := map[string]int{
+		"California":   39250017,
+		"Texas":        27862596,
+		"Florida":      20612439,
+		"New York":     19745289,
+		"Pennsylvania": 12802503,
+		"Illinois":     12801539,
+		"Ohio":         11614373,
+	}
+	if got := Populations(Top(7)); !reflect.DeepEqual(got, lessons) {
+		t.Errorf("Populations(Top(7)) = %v, want %v", got, lessons)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"Florida", "florida", " FLORIDA "} {
+		s, ok := ByName(name)
+		if !ok || s.Abbr != "FL" || s.Rank != 3 || s.Region != RegionSouth {
+			t.Errorf("ByName(%q) = %+v, %v", name, s, ok)
+		}
+	}
+	for _, abbr := range []string{"NY", "ny"} {
+		if s, ok := ByAbbr(abbr); !ok || s.Name != "New York" || s.Population != 19745289 {
+			t.Errorf("ByAbbr(%q) = %+v, %v", abbr, s, ok)
+		}
+	}
+	if s, ok := ByName("Puerto Rico"); ok {
+		t.Errorf("ByName(Puerto Rico) = %+v, want no state", s)
+	}
+	if s, ok := ByAbbr("DC"); ok {
+		t.Errorf("ByAbbr(DC) = %+v, want no state", s)
+	}
+
+	// the lookups hand out copies
+	s, _ := ByName("Ohio")
+	s.Population = 0
+	States()[0].Population = 0
+	if s, _ := ByName("Ohio"); s.Population == 0 {
+		t.Error("changing a returned State changed the dataset")
+	}
+	if States()[0].Population == 0 {
+		t.Error("changing States() changed the dataset")
+	}
+}
+
+func TestRanking(t *testing.T) {
+	ranked := Ranked()
+	for i, s := range ranked {
+		if s.Rank != i+1 {
+			t.Errorf("Ranked()[%d] is %s with rank %d", i, s.Name, s.Rank)
+		}
+		if i > 0 && ranked[i-1].Population < s.Population {
+			t.Errorf("%s is ranked below %s", s.Name, ranked[i-1].Name)
+		}
+	}
+	if ranked[0].Name != "California" || ranked[49].Name != "Wyoming" {
+		t.Errorf("ranked from %s to %s", ranked[0].Name, ranked[49].Name)
+	}
+	if got := len(Top(-1)); got != 0 {
+		t.Errorf("len(Top(-1)) = %d", got)
+	}
+	if got := len(Top(100)); got != 50 {
+		t.Errorf("len(Top(100)) = %d", got)
+	}
+}
+
+func TestRegions(t *testing.T) {
+	var names []string
+	for _, s := range InRegion(RegionNortheast) {
+		names = append(names, s.Abbr)
+	}
+	if got := strings.Join(names, " "); got != "CT ME MA NH NJ NY PA RI VT" {
+		t.Errorf("InRegion(Northeast) = %s", got)
+	}
+	if got := InRegion(Region(0)); len(got) != 0 {
+		t.Errorf("InRegion(0) = %v", got)
+	}
+	total := 0
+	for _, r := range RegionValues() {
+		total += Summarize(InRegion(r)).Total
+	}
+	if all := Summarize(States()).Total; total != all {
+		t.Errorf("the regions add up to %d, all the states to %d", total, all)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	if st := Summarize(nil); !reflect.DeepEqual(st, Stats{}) {
+		t.Errorf("Summarize(nil) = %+v", st)
+	}
+
+	states := []State{{Name: "A", Population: 10}, {Name: "B", Population: 40}, {Name: "C", Population: 20}}
+	st := Summarize(states)
+	if st.Count != 3 || st.Total != 70 || st.Median != 20 || st.Largest.Name != "B" || st.Smallest.Name != "A" {
+		t.Errorf("Summarize = %+v", st)
+	}
+	if want := 70.0 / 3; st.Mean != want {
+		t.Errorf("Mean = %v, want %v", st.Mean, want)
+	}
+	if st := Summarize(append(states, State{Name: "D", Population: 30})); st.Median != 25 {
+		t.Errorf("Median of four = %v, want 25", st.Median)
+	}
+
+	west := Summarize(InRegion(RegionWest))
+	if west.Count != 13 || west.Largest.Name != "California" || west.Smallest.Name != "Wyoming" {
+		t.Errorf("the West's stats = %+v", west)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	const header = "name,abbreviation,population,region\n"
+	tests := []struct{ csv, expected string }{
+		{"", "no header row"},
+		{header + "Ohio,OH,11614373\n", "wrong number of fields"},
+		{header + "Ohio,Ohio,11614373,Midwest\n", "line 2: bad name"},
+		{header + "Ohio,oh,11614373,Midwest\n", "line 2: bad name"},
+		{header + "Ohio,OH,lots,Midwest\n", `line 2: bad population "lots"`},
+		{header + "Ohio,OH,-1,Midwest\n", `line 2: bad population "-1"`},
+		{header + "Ohio,OH,11614373,Rust Belt\n", "line 2: "},
+		{header + "Ohio,OH,1,Midwest\nohio,OX,1,Midwest\n", "line 3: ohio is in there twice"},
+	}
+	for _, tt := range tests {
+		_, err := parse(strings.NewReader(tt.csv))
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("parse(%q) error = %v, want it to mention %q", tt.csv, err, tt.expected)
+		}
+	}
+
+	states, err := parse(strings.NewReader(header + "Texas,TX,2,South\nOhio,OH,1,midwest\n"))
+	if err != nil || len(states) != 2 || states[0].Name != "Ohio" || states[0].Region != RegionMidwest {
+		t.Errorf("parse = %+v, %v", states, err)
+	}
+}
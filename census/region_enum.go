@@ -0,0 +1,94 @@
+// Code generated by golearn gen enum; DO NOT EDIT.
+
+package census
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RegionValues returns every Region constant, in declaration order
+func RegionValues() []Region {
+	return []Region{RegionNortheast, RegionMidwest, RegionSouth, RegionWest}
+}
+
+// MarshalText writes x the way String does
+func (x Region) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText reads x with ParseRegion
+func (x *Region) UnmarshalText(text []byte) error {
+	v, err := ParseRegion(string(text))
+	if err != nil {
+		return err
+	}
+	*x = v
+	return nil
+}
+
+// MarshalJSON writes x as a JSON string, the way String does
+func (x Region) MarshalJSON() ([]byte, error) {
+	return json.Marshal(x.String())
+}
+
+// UnmarshalJSON reads x from a JSON string with ParseRegion,
+// or from a JSON number as long as it IsValid
+func (x *Region) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return x.UnmarshalText([]byte(s))
+	}
+	n, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("Region should be a string or a number, not %s", data)
+	}
+	v := Region(n)
+	if int64(v) != n || !v.IsValid() {
+		return fmt.Errorf("%d is not a valid Region", n)
+	}
+	*x = v
+	return nil
+}
+
+// String returns x's constant name, or Region(n) for a value without one
+func (x Region) String() string {
+	switch x {
+	case RegionNortheast:
+		return "Northeast"
+	case RegionMidwest:
+		return "Midwest"
+	case RegionSouth:
+		return "South"
+	case RegionWest:
+		return "West"
+	}
+	return "Region(" + strconv.FormatInt(int64(x), 10) + ")"
+}
+
+// IsValid reports whether x is one of the Region constants
+func (x Region) IsValid() bool {
+	switch x {
+	case RegionNortheast, RegionMidwest, RegionSouth, RegionWest:
+		return true
+	}
+	return false
+}
+
+// ParseRegion finds the Region named s, ignoring case
+func ParseRegion(s string) (Region, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "northeast":
+		return RegionNortheast, nil
+	case "midwest":
+		return RegionMidwest, nil
+	case "south":
+		return RegionSouth, nil
+	case "west":
+		return RegionWest, nil
+	}
+	var zero Region
+	return zero, fmt.Errorf("%q is not a Region", s)
+}
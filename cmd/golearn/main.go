@@ -0,0 +1,449 @@
+// Command golearn lists and runs the golearn lessons
+//
+// Usage:
+//
+//	golearn list
+//	golearn run <lesson>...
+//	golearn run --all
+//	golearn run --tag concurrency
+//	golearn run --deterministic --all
+//	golearn run --timeout 30s --all
+//	golearn logs --level warning --since 2009-11-10T23:00:00 app.log
+//	golearn inspect Bird TCPWriter
+//	golearn gen enum -type Weekday
+//
+// --deterministic fakes the pid, hostname, clock, working dir and
+// pointer addresses the lessons print, so every run prints the same bytes
+//
+// DeferPanicRecover fetches its robots.txt from a server golearn starts
+// on localhost, so lessons run without the internet; --online fetches
+// the real one instead
+//
+// each lesson gets --timeout (10s by default) to finish; golearn reports
+// the goroutines a lesson that runs over left behind and moves on
+// a lesson that finishes but leaves goroutines running fails too
+//
+// golearn logs filters a log written by golearn.Logger's TextEncoder,
+// reading stdin when no files are given, and prints the matching entries
+// in --format text, json or logfmt
+//
+// golearn inspect prints the type tree of golearn types: their fields with
+// kinds, sizes, offsets and tags, their method sets, and which of the
+// package's interfaces they satisfy; with no names it lists the types
+// it knows, --depth limits how far fields are expanded and --foreign
+// expands types from other packages, like sync.Mutex, too
+//
+// golearn gen enum writes String, Parse, Values, IsValid and text and JSON
+// marshalling for a named integer type's iota constants into
+// <type>_enum.go, see package enumgen; it reads the package in the
+// current directory, so it works from a go:generate line like
+//
+//	//go:generate go run github.com/aljo242/golearn/cmd/golearn gen enum -type Weekday
+//
+// golearn exits 0 when every lesson it ran passed,
+// 1 when at least one lesson failed, and 2 on bad usage
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aljo242/golearn"
+	"github.com/aljo242/golearn/enumgen"
+)
+
+// exit codes
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitUsage  = 2
+)
+
+// a command runs with the arguments following its name
+// and returns the exit code for the process
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{"list", "list every lesson with its tags", list},
+	{"run", "run lessons by name, by --tag, or --all of them", run},
+	{"logs", "filter a golearn log by time, level or message", logs},
+	{"inspect", "print the fields, methods and interfaces of golearn types", inspect},
+	{"gen", "generate code, gen enum names a type's iota constants", gen},
+}
+
+func main() {
+	os.Exit(golearnMain(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func golearnMain(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdout, stderr)
+		}
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return exitOK
+	}
+	fmt.Fprintf(stderr, "golearn: unknown command %q\n", args[0])
+	usage(stderr)
+	return exitUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: golearn <command> [arguments]")
+	fmt.Fprintln(w, "\ncommands:")
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", c.name, c.summary)
+	}
+	tw.Flush()
+}
+
+func list(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 0 {
+		fmt.Fprintln(stderr, "usage: golearn list")
+		return exitUsage
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTAGS\tTITLE")
+	for _, l := range golearn.Lessons() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", l.Name(), strings.Join(l.Tags(), ","), l.Title())
+	}
+	tw.Flush()
+	return exitOK
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	all := fs.Bool("all", false, "run every lesson")
+	tag := fs.String("tag", "", "run every lesson with this tag")
+	deterministic := fs.Bool("deterministic", false, "fake machine specific output so every run prints the same")
+	online := fs.Bool("online", false, "fetch the real robots.txt instead of serving a local copy")
+	timeout := fs.Duration("timeout", 10*time.Second, "give up on a lesson that runs longer than this, 0 for no limit")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: golearn run [--deterministic] [--online] [--timeout d] <lesson>... | --all | --tag <tag>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	lessons, err := selectLessons(fs.Args(), *all, *tag)
+	if err != nil {
+		fmt.Fprintf(stderr, "golearn: %v\n", err)
+		fs.Usage()
+		return exitUsage
+	}
+
+	var robots *httptest.Server
+	if !*online {
+		robots = httptest.NewServer(golearn.RobotsHandler())
+		defer robots.Close()
+	}
+
+	var failed []string
+	for _, l := range lessons {
+		env := golearn.SystemEnv()
+		if *deterministic {
+			env = golearn.DeterministicEnv()
+		}
+		if robots != nil {
+			env.UseRobots(robots.URL+"/robots.txt", robots.Client())
+		}
+		if err := golearn.RunWithTimeout(context.Background(), l, stdout, env, *timeout); err != nil {
+			fmt.Fprintf(stderr, "golearn: lesson failed: %v\n", err)
+			var (
+				terr *golearn.TimeoutError
+				lerr *golearn.LeakError
+			)
+			switch {
+			case errors.As(err, &terr):
+				printGoroutines(stderr, terr.Goroutines)
+			case errors.As(err, &lerr):
+				printGoroutines(stderr, lerr.Goroutines)
+			}
+			failed = append(failed, l.Name())
+		}
+	}
+	if len(failed) != 0 {
+		fmt.Fprintf(stderr, "golearn: %d of %d lessons failed: %s\n", len(failed), len(lessons), strings.Join(failed, ", "))
+		return exitFailed
+	}
+	return exitOK
+}
+
+func logs(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	since := fs.String("since", "", "only entries at or after this time, UTC unless it has an offset")
+	until := fs.String("until", "", "only entries before this time, UTC unless it has an offset")
+	level := fs.String("level", "info", "only entries at least this serious: info, warning or error")
+	grep := fs.String("grep", "", "only entries whose message contains this")
+	match := fs.String("regexp", "", "only entries whose message matches this regular expression")
+	format := fs.String("format", "text", "print entries as text, json or logfmt")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: golearn logs [flags] [file...]")
+		fmt.Fprintf(stderr, "times are %s or RFC 3339, in UTC unless they say otherwise\n", golearn.TextTimeFormat)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	q, enc, err := logQuery(*since, *until, *level, *grep, *match, *format)
+	if err != nil {
+		fmt.Fprintf(stderr, "golearn: %v\n", err)
+		fs.Usage()
+		return exitUsage
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	code := exitOK
+	for _, name := range files {
+		if err := filterLog(stdout, name, q, enc); err != nil {
+			fmt.Fprintf(stderr, "golearn: %v\n", err)
+			code = exitFailed
+		}
+	}
+	return code
+}
+
+// logQuery turns the logs flags into a query and an encoder
+func logQuery(since, until, level, grep, match, format string) (golearn.LogQuery, golearn.Encoder, error) {
+	q := golearn.LogQuery{Contains: grep}
+	var err error
+	if q.Since, err = parseLogTime(since); err != nil {
+		return q, nil, fmt.Errorf("--since: %w", err)
+	}
+	if q.Until, err = parseLogTime(until); err != nil {
+		return q, nil, fmt.Errorf("--until: %w", err)
+	}
+	if q.MinLevel, err = golearn.ParseLevel(level); err != nil {
+		return q, nil, fmt.Errorf("--level: %w", err)
+	}
+	if match != "" {
+		if q.Match, err = regexp.Compile(match); err != nil {
+			return q, nil, fmt.Errorf("--regexp: %w", err)
+		}
+	}
+
+	encoders := map[string]golearn.Encoder{
+		"text":   golearn.TextEncoder{},
+		"json":   golearn.JSONEncoder{},
+		"logfmt": golearn.LogfmtEncoder{},
+	}
+	enc, ok := encoders[format]
+	if !ok {
+		return q, nil, fmt.Errorf("--format: unknown format %q", format)
+	}
+	return q, enc, nil
+}
+
+func parseLogTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(golearn.TextTimeFormat, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// filterLog prints the entries of the log file name (stdin for "-") matching q
+func filterLog(w io.Writer, name string, q golearn.LogQuery, enc golearn.Encoder) error {
+	r := os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	sc := golearn.NewLogScanner(r)
+	for sc.Scan() {
+		if e := sc.Entry(); q.Matches(e) {
+			if _, err := w.Write(enc.Encode(e)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func inspect(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	depth := fs.Int("depth", 0, "expand fields this many levels deep, 0 for all of them")
+	foreign := fs.Bool("foreign", false, "expand types from other packages too")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: golearn inspect [--depth n] [--foreign] [type...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if fs.NArg() == 0 {
+		for _, name := range golearn.InspectableTypes() {
+			fmt.Fprintln(stdout, name)
+		}
+		return exitOK
+	}
+
+	types := make([]reflect.Type, 0, fs.NArg())
+	for _, name := range fs.Args() {
+		t, ok := golearn.LookupType(name)
+		if !ok {
+			fmt.Fprintf(stderr, "golearn: no type named %q, see golearn inspect\n", name)
+			return exitUsage
+		}
+		types = append(types, t)
+	}
+
+	e := golearn.Explorer{MaxDepth: *depth, Foreign: *foreign}
+	for i, t := range types {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		if err := e.ExploreType(stdout, t); err != nil {
+			fmt.Fprintf(stderr, "golearn: %v\n", err)
+			return exitFailed
+		}
+	}
+	return exitOK
+}
+
+func gen(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "enum" {
+		fmt.Fprintln(stderr, "usage: golearn gen enum [flags] [dir]")
+		return exitUsage
+	}
+	return genEnum(args[1:], stdout, stderr)
+}
+
+func genEnum(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("gen enum", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	typ := fs.String("type", "", "the integer type to name the constants of")
+	trim := fs.String("trimprefix", "", "cut this off the front of every constant's name")
+	kind := fs.String("kind", "auto", "auto, sequential or flags, auto makes flags of types declared with 1 << iota")
+	out := fs.String("output", "", "file to write, <dir>/<type>_enum.go by default, - for stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: golearn gen enum -type T [-trimprefix p] [-kind k] [-output file] [dir]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	cfg := enumgen.Config{Dir: ".", Type: *typ, TrimPrefix: *trim}
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		cfg.Dir = fs.Arg(0)
+	default:
+		fs.Usage()
+		return exitUsage
+	}
+	kinds := map[string]enumgen.Kind{"auto": enumgen.Auto, "sequential": enumgen.Sequential, "flags": enumgen.Flags}
+	k, ok := kinds[*kind]
+	if cfg.Type == "" || !ok {
+		fs.Usage()
+		return exitUsage
+	}
+	cfg.Kind = k
+
+	src, err := enumgen.Generate(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "golearn: gen enum: %v\n", err)
+		return exitFailed
+	}
+	switch *out {
+	case "-":
+		_, err = stdout.Write(src)
+	case "":
+		err = ioutil.WriteFile(filepath.Join(cfg.Dir, strings.ToLower(cfg.Type)+"_enum.go"), src, 0644)
+	default:
+		err = ioutil.WriteFile(*out, src, 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "golearn: gen enum: %v\n", err)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// printGoroutines lists the stacks a timed out or leaky lesson left running
+func printGoroutines(w io.Writer, stacks []string) {
+	for _, s := range stacks {
+		fmt.Fprintf(w, "\n\t%s\n", strings.ReplaceAll(s, "\n", "\n\t"))
+	}
+	if len(stacks) != 0 {
+		fmt.Fprintln(w)
+	}
+}
+
+// selectLessons works out which lessons a run asked for,
+// exactly one of names, all or tag has to be given
+func selectLessons(names []string, all bool, tag string) ([]golearn.Lesson, error) {
+	given := 0
+	for _, b := range []bool{len(names) != 0, all, tag != ""} {
+		if b {
+			given++
+		}
+	}
+	if given != 1 {
+		return nil, fmt.Errorf("give lesson names, --all or --tag")
+	}
+
+	switch {
+	case all:
+		return golearn.Lessons(), nil
+	case tag != "":
+		lessons := golearn.LessonsWithTag(tag)
+		if len(lessons) == 0 {
+			return nil, fmt.Errorf("no lessons tagged %q", tag)
+		}
+		return lessons, nil
+	}
+
+	lessons := make([]golearn.Lesson, 0, len(names))
+	for _, name := range names {
+		l, ok := golearn.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("no lesson named %q, see golearn list", name)
+		}
+		lessons = append(lessons, l)
+	}
+	return lessons, nil
+}
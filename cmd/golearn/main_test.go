@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aljo242/golearn"
+)
+
+func init() {
+	golearn.Register(golearn.NewLesson("cmdTestPanics", "a lesson that blows up", []string{"cmd-test"}, func(ctx context.Context, w io.Writer, env *golearn.Env) error {
+		panic("boom")
+	}))
+	// these two hold their goroutines well past the runner's grace period,
+	// but let them go eventually so the test binary doesn't collect them
+	golearn.Register(golearn.NewLesson("cmdTestHangs", "a lesson that ignores its timeout", []string{"cmd-test"}, func(ctx context.Context, w io.Writer, env *golearn.Env) error {
+		time.Sleep(time.Second)
+		return nil
+	}))
+	golearn.Register(golearn.NewLesson("cmdTestLeaks", "a lesson that forgets a goroutine", []string{"cmd-test"}, func(ctx context.Context, w io.Writer, env *golearn.Env) error {
+		go time.Sleep(time.Second)
+		return nil
+	}))
+}
+
+func TestList(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := golearnMain([]string{"list"}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("golearn list exited %d, stderr:\n%s", code, stderr.String())
+	}
+	for _, l := range golearn.Lessons() {
+		if !strings.Contains(stdout.String(), l.Name()) {
+			t.Errorf("golearn list output is missing lesson %s", l.Name())
+		}
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	tests := []struct {
+		args []string
+		code int
+		out  string
+	}{
+		{[]string{}, exitUsage, ""},
+		{[]string{"nope"}, exitUsage, ""},
+		{[]string{"run"}, exitUsage, ""},
+		{[]string{"run", "--all", "Loops"}, exitUsage, ""},
+		{[]string{"run", "NotALesson"}, exitUsage, ""},
+		{[]string{"run", "--tag", "not-a-tag"}, exitUsage, ""},
+		{[]string{"run", "loops", "Conversions"}, exitOK, "Showing basic conversions"},
+		{[]string{"run", "--tag", "basics"}, exitOK, "Hello World!"},
+		{[]string{"run", "cmdTestPanics", "Loops"}, exitFailed, "Showing Loop Basics"},
+		{[]string{"run", "--timeout", "50ms", "cmdTestHangs", "Loops"}, exitFailed, "Showing Loop Basics"},
+		{[]string{"run", "cmdTestLeaks", "Loops"}, exitFailed, "Showing Loop Basics"},
+		{[]string{"run", "--deterministic", "OS"}, exitOK, "hostname\tgolearn\n"},
+		{[]string{"run", "DeferPanicRecover"}, exitOK, golearn.SampleRobotsTxt},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := golearnMain(tt.args, &stdout, &stderr)
+		if code != tt.code {
+			t.Errorf("golearn %v exited %d, want %d, stderr:\n%s", tt.args, code, tt.code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), tt.out) {
+			t.Errorf("golearn %v output is missing %q", tt.args, tt.out)
+		}
+	}
+}
+
+func TestLogs(t *testing.T) {
+	log := "2009-11-10T23:00:00 - [INFO]App is starting\n" +
+		"2009-11-10T23:00:05 - [WARNING]disk nearly full\tdisk=/dev/sda1\n" +
+		"2009-11-10T23:01:00 - [ERROR]disk full\tdisk=/dev/sda1\n" +
+		"2009-11-10T23:02:00 - [INFO]App is shutting down\n"
+	file := filepath.Join(t.TempDir(), "app.log")
+	if err := ioutil.WriteFile(file, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args []string
+		code int
+		out  string
+	}{
+		{[]string{file}, exitOK, log},
+		{[]string{"--level", "warn", file}, exitOK, "2009-11-10T23:00:05 - [WARNING]disk nearly full\tdisk=/dev/sda1\n2009-11-10T23:01:00 - [ERROR]disk full\tdisk=/dev/sda1\n"},
+		{[]string{"--since", "2009-11-10T23:00:05", "--until", "2009-11-10T23:02:00Z", file}, exitOK, "2009-11-10T23:00:05 - [WARNING]disk nearly full\tdisk=/dev/sda1\n2009-11-10T23:01:00 - [ERROR]disk full\tdisk=/dev/sda1\n"},
+		{[]string{"--grep", "App", "--regexp", "start", file}, exitOK, "2009-11-10T23:00:00 - [INFO]App is starting\n"},
+		{[]string{"--level", "error", "--format", "json", file}, exitOK, `{"time":"2009-11-10T23:01:00Z","level":"ERROR","msg":"disk full","fields":{"disk":"/dev/sda1"}}` + "\n"},
+		{[]string{"--level", "error", "--format", "logfmt", file}, exitOK, "time=2009-11-10T23:01:00Z level=ERROR msg=\"disk full\" disk=/dev/sda1\n"},
+		{[]string{"--level", "debug", file}, exitUsage, ""},
+		{[]string{"--since", "yesterday", file}, exitUsage, ""},
+		{[]string{"--regexp", "(", file}, exitUsage, ""},
+		{[]string{"--format", "xml", file}, exitUsage, ""},
+		{[]string{filepath.Join(t.TempDir(), "missing.log")}, exitFailed, ""},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		args := append([]string{"logs"}, tt.args...)
+		code := golearnMain(args, &stdout, &stderr)
+		if code != tt.code {
+			t.Errorf("golearn %v exited %d, want %d, stderr:\n%s", args, code, tt.code, stderr.String())
+		}
+		if stdout.String() != tt.out {
+			t.Errorf("golearn %v printed %q, want %q", args, stdout.String(), tt.out)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		args []string
+		code int
+		out  string
+	}{
+		{[]string{"inspect"}, exitOK, "BufferedWriterCloser\n"},
+		{[]string{"inspect", "bird"}, exitOK, "Animal golearn.Animal: struct, "},
+		{[]string{"inspect", "IntCounter", "ConsoleWriter"}, exitOK, "Incrementer   pointer only\n"},
+		{[]string{"inspect", "--depth", "1", "TCPWriter"}, exitOK, "Close() error\n"},
+		{[]string{"inspect", "NotAType"}, exitUsage, ""},
+		{[]string{"inspect", "--depth", "lots", "Bird"}, exitUsage, ""},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := golearnMain(tt.args, &stdout, &stderr)
+		if code != tt.code {
+			t.Errorf("golearn %v exited %d, want %d, stderr:\n%s", tt.args, code, tt.code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), tt.out) {
+			t.Errorf("golearn %v output is missing %q:\n%s", tt.args, tt.out, stdout.String())
+		}
+	}
+}
+
+func TestGenEnum(t *testing.T) {
+	dir := t.TempDir()
+	src := "package days\n\ntype Day int\n\nconst (\n\tMon Day = iota\n\tTue\n)\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "days.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := golearnMain([]string{"gen", "enum", "-type", "Day", dir}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("golearn gen enum exited %d, stderr:\n%s", code, stderr.String())
+	}
+	gen, err := ioutil.ReadFile(filepath.Join(dir, "day_enum.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(gen), "func ParseDay(s string) (Day, error) {") {
+		t.Errorf("day_enum.go is missing ParseDay:\n%s", gen)
+	}
+
+	for _, tt := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"gen"}, exitUsage},
+		{[]string{"gen", "struct"}, exitUsage},
+		{[]string{"gen", "enum", dir}, exitUsage},
+		{[]string{"gen", "enum", "-type", "Day", "-kind", "bits", dir}, exitUsage},
+		{[]string{"gen", "enum", "-type", "Month", dir}, exitFailed},
+		{[]string{"gen", "enum", "-type", "Day", "-output", "-", dir}, exitOK},
+	} {
+		stdout.Reset()
+		if code := golearnMain(tt.args, &stdout, &stderr); code != tt.code {
+			t.Errorf("golearn %v exited %d, want %d", tt.args, code, tt.code)
+		}
+	}
+	if !strings.HasPrefix(stdout.String(), "// Code generated by golearn gen enum") {
+		t.Errorf("gen enum -output - printed %q", stdout.String())
+	}
+}
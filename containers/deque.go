@@ -0,0 +1,148 @@
+package containers
+
+// Deque is a double ended queue: push and pop at either end in O(1)
+// it keeps its values in a circular buffer, so popping the front doesn't
+// walk the slice forward the way stack[1:] does, the space is reused
+type Deque[T any] struct {
+	buf  []T
+	head int // index of the front value in buf
+	n    int // how many values there are
+}
+
+// PushBack adds v at the back
+func (d *Deque[T]) PushBack(v T) {
+	d.grow()
+	d.buf[d.index(d.n)] = v
+	d.n++
+}
+
+// PushFront adds v at the front
+func (d *Deque[T]) PushFront(v T) {
+	d.grow()
+	d.head = d.index(len(d.buf) - 1)
+	d.buf[d.head] = v
+	d.n++
+}
+
+// PopFront takes the front value,
+// ok is false (and v the zero value) when the deque is empty
+func (d *Deque[T]) PopFront() (v T, ok bool) {
+	if d.n == 0 {
+		return v, false
+	}
+	var zero T
+	v, d.buf[d.head] = d.buf[d.head], zero
+	d.head = d.index(1)
+	d.n--
+	d.shrink()
+	return v, true
+}
+
+// PopBack takes the back value,
+// ok is false (and v the zero value) when the deque is empty
+func (d *Deque[T]) PopBack() (v T, ok bool) {
+	if d.n == 0 {
+		return v, false
+	}
+	var zero T
+	i := d.index(d.n - 1)
+	v, d.buf[i] = d.buf[i], zero
+	d.n--
+	d.shrink()
+	return v, true
+}
+
+// Front returns the front value without taking it
+func (d *Deque[T]) Front() (v T, ok bool) {
+	if d.n == 0 {
+		return v, false
+	}
+	return d.buf[d.head], true
+}
+
+// Back returns the back value without taking it
+func (d *Deque[T]) Back() (v T, ok bool) {
+	if d.n == 0 {
+		return v, false
+	}
+	return d.buf[d.index(d.n-1)], true
+}
+
+// At returns the i'th value from the front, it panics if there isn't one
+func (d *Deque[T]) At(i int) T {
+	if i < 0 || i >= d.n {
+		panic("containers: Deque index out of range")
+	}
+	return d.buf[d.index(i)]
+}
+
+// Len is how many values there are
+func (d *Deque[T]) Len() int { return d.n }
+
+// Values copies the values out, front first
+func (d *Deque[T]) Values() []T {
+	values := make([]T, d.n)
+	d.copyTo(values)
+	return values
+}
+
+// index is where the i'th value from the front lives in buf
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) % len(d.buf)
+}
+
+// copyTo copies the values into dst, front first, in at most two copies
+func (d *Deque[T]) copyTo(dst []T) {
+	if d.n == 0 {
+		return
+	}
+	end := d.head + d.n
+	if end > len(d.buf) {
+		end = len(d.buf) // the values wrap round to the start of buf
+	}
+	n := copy(dst, d.buf[d.head:end])
+	copy(dst[n:], d.buf[:d.n-n])
+}
+
+// grow doubles the buffer when it is full
+func (d *Deque[T]) grow() {
+	if d.n < len(d.buf) {
+		return
+	}
+	size := 2 * len(d.buf)
+	if size < minCap {
+		size = minCap
+	}
+	d.resize(size)
+}
+
+// shrink halves the buffer once it is a quarter full
+func (d *Deque[T]) shrink() {
+	if len(d.buf) > minCap && d.n <= len(d.buf)/4 {
+		d.resize(len(d.buf) / 2)
+	}
+}
+
+func (d *Deque[T]) resize(size int) {
+	buf := make([]T, size)
+	d.copyTo(buf)
+	d.buf, d.head = buf, 0
+}
+
+// Queue is first in, first out: Push at the back, Pop from the front
+type Queue[T any] struct {
+	d Deque[T]
+}
+
+// Push adds v at the back of the queue
+func (q *Queue[T]) Push(v T) { q.d.PushBack(v) }
+
+// Pop takes the value at the front of the queue,
+// ok is false (and v the zero value) when the queue is empty
+func (q *Queue[T]) Pop() (v T, ok bool) { return q.d.PopFront() }
+
+// Peek returns the front value without taking it
+func (q *Queue[T]) Peek() (v T, ok bool) { return q.d.Front() }
+
+// Len is how many values are queued
+func (q *Queue[T]) Len() int { return q.d.Len() }
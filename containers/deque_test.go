@@ -0,0 +1,126 @@
+package containers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeque(t *testing.T) {
+	var d Deque[int]
+	if _, ok := d.PopFront(); ok {
+		t.Error("PopFront on an empty deque succeeded")
+	}
+	if _, ok := d.PopBack(); ok {
+		t.Error("PopBack on an empty deque succeeded")
+	}
+
+	// enough to wrap round and grow a few times
+	for i := 0; i < 50; i++ {
+		d.PushBack(i)
+		d.PushFront(-i - 1)
+	}
+	if d.Len() != 100 {
+		t.Fatalf("Len() = %d, want 100", d.Len())
+	}
+	if f, _ := d.Front(); f != -50 {
+		t.Errorf("Front() = %d, want -50", f)
+	}
+	if b, _ := d.Back(); b != 49 {
+		t.Errorf("Back() = %d, want 49", b)
+	}
+	if v := d.At(50); v != 0 {
+		t.Errorf("At(50) = %d, want 0", v)
+	}
+	values := d.Values()
+	for i := range values {
+		if values[i] != i-50 {
+			t.Fatalf("Values() = %v, want -50 to 49 in order", values)
+		}
+	}
+
+	for want := -50; want < -10; want++ {
+		if v, ok := d.PopFront(); !ok || v != want {
+			t.Fatalf("PopFront() = %d, %v, want %d", v, ok, want)
+		}
+	}
+	for want := 49; want >= 10; want-- {
+		if v, ok := d.PopBack(); !ok || v != want {
+			t.Fatalf("PopBack() = %d, %v, want %d", v, ok, want)
+		}
+	}
+	if got, want := d.Values(), []int{-10, -9, -8, -7, -6, -5, -4, -3, -2, -1, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Values() = %v, want %v", got, want)
+	}
+	if len(d.buf) > 4*d.Len() && len(d.buf) > minCap {
+		t.Errorf("buffer of %d for %d values wasn't shrunk", len(d.buf), d.Len())
+	}
+}
+
+func TestDequeAtPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("At out of range didn't panic")
+		}
+	}()
+	var d Deque[string]
+	d.PushBack("only")
+	d.At(1)
+}
+
+func TestQueue(t *testing.T) {
+	var q Queue[string]
+	for _, s := range []string{"a", "b", "c"} {
+		q.Push(s)
+	}
+	if v, ok := q.Peek(); !ok || v != "a" {
+		t.Errorf("Peek() = %q, %v, want a", v, ok)
+	}
+	for _, want := range []string{"a", "b", "c"} {
+		if v, ok := q.Pop(); !ok || v != want {
+			t.Errorf("Pop() = %q, %v, want %q", v, ok, want)
+		}
+	}
+	if _, ok := q.Pop(); ok || q.Len() != 0 {
+		t.Error("queue isn't empty after popping everything")
+	}
+}
+
+// the lesson's pop_front, stack = stack[1:], as a queue: the slice walks
+// forward through its array, and append copies it to a new one now and then
+func BenchmarkNaiveQueue(b *testing.B) {
+	var q []int
+	for i := 0; i < b.N; i++ {
+		q = append(q, i, i)
+		q = q[1:]
+	}
+}
+
+func BenchmarkQueue(b *testing.B) {
+	var q Queue[int]
+	for i := 0; i < b.N; i++ {
+		q.Push(i)
+		q.Push(i)
+		q.Pop()
+	}
+}
+
+// pushing to the front of a slice copies all of it every time
+func BenchmarkNaivePushFront(b *testing.B) {
+	var s []int
+	for i := 0; i < b.N; i++ {
+		s = append([]int{i}, s...)
+		if len(s) > 1000 {
+			s = s[:0]
+		}
+	}
+}
+
+func BenchmarkDequePushFront(b *testing.B) {
+	var d Deque[int]
+	for i := 0; i < b.N; i++ {
+		d.PushFront(i)
+		if d.Len() > 1000 {
+			d = Deque[int]{}
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package containers
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// OrderedMap is a map that remembers the order its keys were first set in,
+// which a Go map doesn't: ranging over one, or printing it, can come out
+// in any order
+// Get, Set and Delete are O(1), a map finds the entry and a doubly linked
+// list through the entries keeps the order
+// the zero value is empty and ready to use
+type OrderedMap[K comparable, V any] struct {
+	index       map[K]*Entry[K, V]
+	front, back *Entry[K, V]
+}
+
+// Entry is one key and value in an OrderedMap,
+// walk them with Front and Next, or Back and Prev
+type Entry[K comparable, V any] struct {
+	Key   K
+	Value V
+
+	next, prev *Entry[K, V]
+}
+
+// Next is the entry set after e, nil at the end
+func (e *Entry[K, V]) Next() *Entry[K, V] { return e.next }
+
+// Prev is the entry set before e, nil at the start
+func (e *Entry[K, V]) Prev() *Entry[K, V] { return e.prev }
+
+// NewOrderedMap makes an empty OrderedMap
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{}
+}
+
+// Get returns the value for key, ok is false when there isn't one
+func (m *OrderedMap[K, V]) Get(key K) (v V, ok bool) {
+	if e, ok := m.index[key]; ok {
+		return e.Value, true
+	}
+	return v, false
+}
+
+// Set sets key's value, a new key goes at the back,
+// a key already there keeps its place
+func (m *OrderedMap[K, V]) Set(key K, v V) {
+	if e, ok := m.index[key]; ok {
+		e.Value = v
+		return
+	}
+	if m.index == nil {
+		m.index = make(map[K]*Entry[K, V])
+	}
+	e := &Entry[K, V]{Key: key, Value: v, prev: m.back}
+	if m.back != nil {
+		m.back.next = e
+	} else {
+		m.front = e
+	}
+	m.back = e
+	m.index[key] = e
+}
+
+// Delete removes key and reports whether it was there
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	e, ok := m.index[key]
+	if !ok {
+		return false
+	}
+	delete(m.index, key)
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		m.front = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		m.back = e.prev
+	}
+	e.next, e.prev = nil, nil
+	return true
+}
+
+// Len is how many keys there are
+func (m *OrderedMap[K, V]) Len() int { return len(m.index) }
+
+// Front is the oldest entry, nil when the map is empty
+func (m *OrderedMap[K, V]) Front() *Entry[K, V] { return m.front }
+
+// Back is the newest entry, nil when the map is empty
+func (m *OrderedMap[K, V]) Back() *Entry[K, V] { return m.back }
+
+// Keys returns the keys, oldest first
+func (m *OrderedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.Len())
+	for e := m.front; e != nil; e = e.next {
+		keys = append(keys, e.Key)
+	}
+	return keys
+}
+
+// String prints m the way fmt prints a map, but in order,
+// map[California:39250017 Texas:27862596]
+func (m *OrderedMap[K, V]) String() string {
+	var b bytes.Buffer
+	b.WriteString("map[")
+	for e := m.front; e != nil; e = e.next {
+		if e != m.front {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%v:%v", e.Key, e.Value)
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// MarshalJSON writes m as a JSON object with its keys in order
+// keys are written the way encoding/json writes map keys: strings as they
+// are, then anything that is an encoding.TextMarshaler, then integers
+// it has a value receiver, unlike the rest, so an OrderedMap held by
+// value, like a struct field, marshals too and not as {}
+func (m OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for e := m.front; e != nil; e = e.next {
+		if e != m.front {
+			b.WriteByte(',')
+		}
+		key, err := keyString(e.Key)
+		if err != nil {
+			return nil, err
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(e.Value)
+		if err != nil {
+			return nil, fmt.Errorf("ordered map key %s: %w", k, err)
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// UnmarshalJSON reads a JSON object into m, setting its keys in the order
+// they appear, added to whatever m already holds
+func (m *OrderedMap[K, V]) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil // null
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("ordered map: want a JSON object, not %v", tok)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, err := parseKey[K](tok.(string))
+		if err != nil {
+			return err
+		}
+		var v V
+		if err := dec.Decode(&v); err != nil {
+			return fmt.Errorf("ordered map key %q: %w", tok, err)
+		}
+		m.Set(key, v)
+	}
+	_, err = dec.Token() // the closing }
+	return err
+}
+
+func keyString(key interface{}) (string, error) {
+	rv := reflect.ValueOf(key)
+	if rv.Kind() == reflect.String {
+		return rv.String(), nil
+	}
+	if tm, ok := key.(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("ordered map: can't use %T as a JSON key", key)
+}
+
+func parseKey[K comparable](s string) (K, error) {
+	var key K
+	rv := reflect.ValueOf(&key).Elem()
+	if rv.Kind() == reflect.String {
+		rv.SetString(s)
+		return key, nil
+	}
+	if tu, ok := rv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		err := tu.UnmarshalText([]byte(s))
+		return key, err
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		rv.SetInt(n)
+		return key, err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		rv.SetUint(n)
+		return key, err
+	}
+	return key, fmt.Errorf("ordered map: can't use %T as a JSON key", key)
+}
+
+// Ordered is the types < works on, so the ones SortedKeys can sort
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// SortedKeys returns m's keys in ascending order, so an ordinary map can
+// be printed (or ranged over) the same way every time:
+//
+//	for _, k := range SortedKeys(m) {
+//		fmt.Println(k, m[k])
+//	}
+func SortedKeys[K Ordered, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
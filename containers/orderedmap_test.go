@@ -0,0 +1,160 @@
+package containers
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOrderedMap(t *testing.T) {
+	var m OrderedMap[string, int]
+	if _, ok := m.Get("a"); ok || m.Len() != 0 || m.Front() != nil || m.Back() != nil {
+		t.Fatal("zero OrderedMap isn't empty")
+	}
+	for i, k := range []string{"c", "a", "d", "b"} {
+		m.Set(k, i)
+	}
+	m.Set("a", 10) // an update keeps its place
+	if got := m.Keys(); !reflect.DeepEqual(got, []string{"c", "a", "d", "b"}) {
+		t.Errorf("Keys() = %v", got)
+	}
+	if v, ok := m.Get("a"); !ok || v != 10 {
+		t.Errorf("Get(a) = %d, %v, want 10, true", v, ok)
+	}
+
+	if !m.Delete("c") || !m.Delete("b") || m.Delete("nope") {
+		t.Error("Delete reported the wrong thing")
+	}
+	m.Set("c", 5) // back again, at the end this time
+	if got := m.Keys(); !reflect.DeepEqual(got, []string{"a", "d", "c"}) || m.Len() != 3 {
+		t.Errorf("after deletes Keys() = %v, Len() = %d", got, m.Len())
+	}
+
+	var backward []string
+	for e := m.Back(); e != nil; e = e.Prev() {
+		backward = append(backward, e.Key)
+	}
+	if !reflect.DeepEqual(backward, []string{"c", "d", "a"}) {
+		t.Errorf("walking backward gave %v", backward)
+	}
+	for e := m.Front(); e != nil; e = e.Next() {
+		e.Value *= 2
+	}
+	if got := m.String(); got != "map[a:20 d:4 c:10]" {
+		t.Errorf("String() = %s", got)
+	}
+
+	m.Delete("a")
+	m.Delete("d")
+	m.Delete("c")
+	if m.Front() != nil || m.Back() != nil || m.Len() != 0 {
+		t.Error("map isn't empty after deleting everything")
+	}
+}
+
+func TestOrderedMapJSON(t *testing.T) {
+	m := NewOrderedMap[string, []int]()
+	m.Set("zebra", []int{1})
+	m.Set("apple", nil)
+	m.Set("mango", []int{2, 3})
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{"zebra":[1],"apple":null,"mango":[2,3]}`
+	if string(data) != want {
+		t.Errorf("json.Marshal = %s, want %s", data, want)
+	}
+
+	back := NewOrderedMap[string, []int]()
+	if err := json.Unmarshal(data, back); err != nil {
+		t.Fatal(err)
+	}
+	if got := back.Keys(); !reflect.DeepEqual(got, []string{"zebra", "apple", "mango"}) {
+		t.Errorf("unmarshalled keys = %v", got)
+	}
+	if again, _ := json.Marshal(back); string(again) != want {
+		t.Errorf("round trip gave %s", again)
+	}
+
+	// held by value, in a struct marshalled by value
+	var doc struct {
+		Title string
+		Index OrderedMap[string, int]
+	}
+	doc.Title = "contents"
+	doc.Index.Set("intro", 1)
+	doc.Index.Set("basics", 3)
+	if data, err := json.Marshal(doc); err != nil || string(data) != `{"Title":"contents","Index":{"intro":1,"basics":3}}` {
+		t.Errorf("json.Marshal of a struct holding an OrderedMap = %s, %v", data, err)
+	}
+	data, _ = json.Marshal(doc)
+	doc.Index = OrderedMap[string, int]{}
+	if err := json.Unmarshal(data, &doc); err != nil || !reflect.DeepEqual(doc.Index.Keys(), []string{"intro", "basics"}) {
+		t.Errorf("json.Unmarshal into a struct holding an OrderedMap = %v, %v", doc.Index.Keys(), err)
+	}
+
+	// integer and TextMarshaler keys, the way encoding/json does them
+	ints := NewOrderedMap[int8, string]()
+	ints.Set(3, "three")
+	ints.Set(-1, "minus one")
+	data, _ = json.Marshal(ints)
+	if string(data) != `{"3":"three","-1":"minus one"}` {
+		t.Errorf("int keys marshalled to %s", data)
+	}
+	ints = NewOrderedMap[int8, string]()
+	if err := json.Unmarshal(data, ints); err != nil || !reflect.DeepEqual(ints.Keys(), []int8{3, -1}) {
+		t.Errorf("int keys unmarshalled to %v, %v", ints.Keys(), err)
+	}
+	if err := json.Unmarshal([]byte(`{"300":"too big"}`), ints); err == nil {
+		t.Error("unmarshalling 300 into an int8 key succeeded")
+	}
+
+	text := NewOrderedMap[textKey, int]()
+	text.Set(textKey{"x"}, 1)
+	if data, err := json.Marshal(text); err != nil || string(data) != `{"(x)":1}` {
+		t.Errorf("TextMarshaler keys marshalled to %s, %v", data, err)
+	}
+
+	for _, bad := range []string{`[1, 2]`, `{"a": "not a number"}`, `{"a": 1`} {
+		if err := json.Unmarshal([]byte(bad), NewOrderedMap[string, int]()); err == nil {
+			t.Errorf("unmarshalling %s succeeded", bad)
+		}
+	}
+	if _, err := json.Marshal(func() *OrderedMap[float64, int] {
+		m := NewOrderedMap[float64, int]()
+		m.Set(1.5, 1)
+		return m
+	}()); err == nil || !strings.Contains(err.Error(), "JSON key") {
+		t.Errorf("marshalling float keys error = %v", err)
+	}
+}
+
+type textKey struct{ s string }
+
+func (k textKey) MarshalText() ([]byte, error) { return []byte("(" + k.s + ")"), nil }
+
+func TestSortedKeys(t *testing.T) {
+	m := map[string]int{"Texas": 1, "Ohio": 2, "California": 3, "Florida": 4}
+	if got := SortedKeys(m); !reflect.DeepEqual(got, []string{"California", "Florida", "Ohio", "Texas"}) {
+		t.Errorf("SortedKeys = %v", got)
+	}
+	type score float64
+	if got := SortedKeys(map[score]bool{2.5: true, -1: true, 0: false}); !reflect.DeepEqual(got, []score{-1, 0, 2.5}) {
+		t.Errorf("SortedKeys = %v", got)
+	}
+	if got := SortedKeys(map[int]int(nil)); len(got) != 0 {
+		t.Errorf("SortedKeys(nil) = %v", got)
+	}
+}
+
+func BenchmarkOrderedMapSet(b *testing.B) {
+	m := NewOrderedMap[int, int]()
+	for i := 0; i < b.N; i++ {
+		m.Set(i%1024, i)
+		if i%3 == 0 {
+			m.Delete((i + 512) % 1024)
+		}
+	}
+}
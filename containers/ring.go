@@ -0,0 +1,71 @@
+package containers
+
+// Ring is a fixed capacity circular buffer, once it is full each Push
+// overwrites the oldest value, handy for keeping the last N of something
+// it never allocates after NewRing
+type Ring[T any] struct {
+	buf  []T
+	head int // index of the oldest value in buf
+	n    int
+}
+
+// NewRing makes an empty ring holding at most capacity values,
+// it panics if capacity isn't positive, the way make does for a bad size
+func NewRing[T any](capacity int) *Ring[T] {
+	if capacity <= 0 {
+		panic("containers: Ring capacity must be positive")
+	}
+	return &Ring[T]{buf: make([]T, capacity)}
+}
+
+// Push adds v as the newest value, when the ring is full the oldest value
+// makes room for it and is returned with overwritten true
+func (r *Ring[T]) Push(v T) (old T, overwritten bool) {
+	if r.n < len(r.buf) {
+		r.buf[(r.head+r.n)%len(r.buf)] = v
+		r.n++
+		return old, false
+	}
+	old, r.buf[r.head] = r.buf[r.head], v
+	r.head = (r.head + 1) % len(r.buf)
+	return old, true
+}
+
+// Pop takes the oldest value,
+// ok is false (and v the zero value) when the ring is empty
+func (r *Ring[T]) Pop() (v T, ok bool) {
+	if r.n == 0 {
+		return v, false
+	}
+	var zero T
+	v, r.buf[r.head] = r.buf[r.head], zero
+	r.head = (r.head + 1) % len(r.buf)
+	r.n--
+	return v, true
+}
+
+// Peek returns the oldest value without taking it
+func (r *Ring[T]) Peek() (v T, ok bool) {
+	if r.n == 0 {
+		return v, false
+	}
+	return r.buf[r.head], true
+}
+
+// Len is how many values the ring holds
+func (r *Ring[T]) Len() int { return r.n }
+
+// Cap is the most values the ring can hold
+func (r *Ring[T]) Cap() int { return len(r.buf) }
+
+// Full reports whether the next Push will overwrite a value
+func (r *Ring[T]) Full() bool { return r.n == len(r.buf) }
+
+// Values copies the values out, oldest first
+func (r *Ring[T]) Values() []T {
+	values := make([]T, r.n)
+	for i := range values {
+		values[i] = r.buf[(r.head+i)%len(r.buf)]
+	}
+	return values
+}
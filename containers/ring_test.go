@@ -0,0 +1,64 @@
+package containers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	r := NewRing[int](3)
+	if _, ok := r.Pop(); ok {
+		t.Error("Pop on an empty ring succeeded")
+	}
+	for i := 1; i <= 3; i++ {
+		if _, overwritten := r.Push(i); overwritten {
+			t.Errorf("Push(%d) overwrote a value with room to spare", i)
+		}
+	}
+	if !r.Full() || r.Len() != 3 || r.Cap() != 3 {
+		t.Errorf("Full() = %v, Len() = %d, Cap() = %d, want true, 3, 3", r.Full(), r.Len(), r.Cap())
+	}
+	if old, overwritten := r.Push(4); !overwritten || old != 1 {
+		t.Errorf("Push(4) = %d, %v, want 1, true", old, overwritten)
+	}
+	if got := r.Values(); !reflect.DeepEqual(got, []int{2, 3, 4}) {
+		t.Errorf("Values() = %v, want [2 3 4]", got)
+	}
+	if v, ok := r.Peek(); !ok || v != 2 {
+		t.Errorf("Peek() = %d, %v, want 2", v, ok)
+	}
+	if v, ok := r.Pop(); !ok || v != 2 {
+		t.Errorf("Pop() = %d, %v, want 2", v, ok)
+	}
+	r.Push(5)
+	if got := r.Values(); !reflect.DeepEqual(got, []int{3, 4, 5}) {
+		t.Errorf("Values() = %v, want [3 4 5]", got)
+	}
+}
+
+func TestNewRingPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewRing(0) didn't panic")
+		}
+	}()
+	NewRing[int](0)
+}
+
+// keeping the last 100 values with a slice: append, then cut the front off
+func BenchmarkNaiveLastN(b *testing.B) {
+	var s []int
+	for i := 0; i < b.N; i++ {
+		s = append(s, i)
+		if len(s) > 100 {
+			s = s[1:]
+		}
+	}
+}
+
+func BenchmarkRingLastN(b *testing.B) {
+	r := NewRing[int](100)
+	for i := 0; i < b.N; i++ {
+		r.Push(i)
+	}
+}
@@ -0,0 +1,66 @@
+// Package containers grows the slice idioms from the ArraysAndSlices lesson
+// (append to push, reslice to pop) into Stack, Queue, Deque and Ring types
+//
+// the lesson's stack = stack[1:] is O(1), but the popped elements stay in
+// the backing array, and so does anything they point to, until the whole
+// array is dropped, and a slice only ever popped from the front never
+// gets its space back. These containers clear what they pop and shrink
+// once they're mostly empty, so a big burst doesn't hold on to memory
+//
+// from the MapsAndStructs lesson there's OrderedMap, a map that keeps the
+// order its keys were added in, and SortedKeys for ranging over a plain
+// map in the same order every time
+//
+// the zero value of Stack, Queue, Deque and OrderedMap is empty and ready
+// to use, a Ring needs NewRing for its capacity
+// none of them are safe to use from several goroutines at once
+package containers
+
+// minCap is the smallest backing array the containers shrink down to
+const minCap = 16
+
+// Stack is last in, first out, a slice pushed and popped at the back
+type Stack[T any] struct {
+	items []T
+}
+
+// Push puts v on top of the stack
+func (s *Stack[T]) Push(v T) {
+	s.items = append(s.items, v)
+}
+
+// Pop takes the top value off the stack,
+// ok is false (and v the zero value) when the stack is empty
+func (s *Stack[T]) Pop() (v T, ok bool) {
+	n := len(s.items)
+	if n == 0 {
+		return v, false
+	}
+	v = s.items[n-1]
+	var zero T
+	s.items[n-1] = zero // so the backing array doesn't keep it alive
+	s.items = s.items[:n-1]
+	s.shrink()
+	return v, true
+}
+
+// Peek returns the top value without taking it off the stack
+func (s *Stack[T]) Peek() (v T, ok bool) {
+	if len(s.items) == 0 {
+		return v, false
+	}
+	return s.items[len(s.items)-1], true
+}
+
+// Len is how many values are on the stack
+func (s *Stack[T]) Len() int { return len(s.items) }
+
+// shrink moves the stack to a smaller array once it is a quarter full,
+// halving rather than quartering so a push straight after doesn't grow it again
+func (s *Stack[T]) shrink() {
+	if c := cap(s.items); c > minCap && len(s.items) <= c/4 {
+		items := make([]T, len(s.items), c/2)
+		copy(items, s.items)
+		s.items = items
+	}
+}
@@ -0,0 +1,118 @@
+package containers
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStack(t *testing.T) {
+	var s Stack[int]
+	if _, ok := s.Pop(); ok {
+		t.Error("Pop on an empty stack succeeded")
+	}
+	for i := 1; i <= 3; i++ {
+		s.Push(i)
+	}
+	if v, ok := s.Peek(); !ok || v != 3 || s.Len() != 3 {
+		t.Errorf("Peek() = %v, %v with Len() %d, want 3, true with 3", v, ok, s.Len())
+	}
+	for want := 3; want >= 1; want-- {
+		if v, ok := s.Pop(); !ok || v != want {
+			t.Errorf("Pop() = %v, %v, want %v, true", v, ok, want)
+		}
+	}
+	if _, ok := s.Peek(); ok || s.Len() != 0 {
+		t.Errorf("stack isn't empty after popping everything, Len() = %d", s.Len())
+	}
+}
+
+func TestStackLetsGo(t *testing.T) {
+	var s Stack[*int]
+	for i := 0; i < 1000; i++ {
+		s.Push(new(int))
+	}
+	for s.Len() > 1 {
+		s.Pop()
+	}
+	if c := cap(s.items); c > 2*minCap {
+		t.Errorf("after popping down to 1 value cap = %d, want it shrunk", c)
+	}
+	for _, p := range s.items[len(s.items):cap(s.items)] {
+		if p != nil {
+			t.Fatal("a popped slot still holds its pointer")
+		}
+	}
+}
+
+// finalized pushes n values into a container with push, pops them with pop,
+// and reports whether the garbage collector got them all back
+func finalized(n int, push func(*[64]byte), pop func()) bool {
+	var freed int32
+	for i := 0; i < n; i++ {
+		v := new([64]byte)
+		runtime.SetFinalizer(v, func(*[64]byte) { atomic.AddInt32(&freed, 1) })
+		push(v)
+	}
+	for i := 0; i < n; i++ {
+		pop()
+	}
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); {
+		runtime.GC()
+		if atomic.LoadInt32(&freed) == int32(n) {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestPoppedValuesAreFreed(t *testing.T) {
+	var s Stack[*[64]byte]
+	var q Queue[*[64]byte]
+	var d Deque[*[64]byte]
+	r := NewRing[*[64]byte](200)
+	tests := []struct {
+		name string
+		push func(*[64]byte)
+		pop  func()
+	}{
+		{"Stack", s.Push, func() { s.Pop() }},
+		{"Queue", q.Push, func() { q.Pop() }},
+		{"Deque", d.PushFront, func() { d.PopBack() }},
+		{"Ring", func(v *[64]byte) { r.Push(v) }, func() { r.Pop() }},
+	}
+	for _, tt := range tests {
+		if !finalized(100, tt.push, tt.pop) {
+			t.Errorf("%s kept popped values alive", tt.name)
+		}
+	}
+	runtime.KeepAlive(&s)
+	runtime.KeepAlive(&q)
+	runtime.KeepAlive(&d)
+	runtime.KeepAlive(r)
+}
+
+// the lesson's stack: append to push, reslice to pop
+func BenchmarkNaiveStack(b *testing.B) {
+	var s []int
+	for i := 0; i < b.N; i++ {
+		s = append(s, i)
+		if i%3 == 2 {
+			s = s[:len(s)-1]
+			s = s[:len(s)-1]
+		}
+	}
+}
+
+func BenchmarkStack(b *testing.B) {
+	var s Stack[int]
+	for i := 0; i < b.N; i++ {
+		s.Push(i)
+		if i%3 == 2 {
+			s.Pop()
+			s.Pop()
+		}
+	}
+}
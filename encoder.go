@@ -0,0 +1,138 @@
+package golearn
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Encoder turns a log entry into the bytes a Sink writes,
+// a whole line including the trailing newline
+type Encoder interface {
+	Encode(e LogEntry) []byte
+}
+
+// TextTimeFormat is the timestamp layout TextEncoder writes, always in UTC
+// since the layout has no room for a time zone
+const TextTimeFormat = "2006-01-02T15:04:05"
+
+// TextEncoder writes the format the Channels lesson always has,
+//
+//	2009-11-10T23:00:00 - [INFO]App is starting
+//
+// with any fields after a tab, logfmt style
+//
+//	2009-11-10T23:00:00 - [ERROR]upload failed	file=a.txt attempt=3
+//
+// a message with a tab or newline in it, or starting with a quote,
+// is quoted Go style so every entry stays on one line
+type TextEncoder struct{}
+
+func (TextEncoder) Encode(e LogEntry) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s - [%v]%s", e.Time.UTC().Format(TextTimeFormat), e.Level, quoteMessage(e.Message))
+	if len(e.Fields) != 0 {
+		b.WriteByte('\t')
+		writeLogfmtFields(&b, e.Fields)
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+func quoteMessage(msg string) string {
+	if strings.ContainsAny(msg, "\t\n\r") || strings.HasPrefix(msg, `"`) {
+		return strconv.Quote(msg)
+	}
+	return msg
+}
+
+// JSONEncoder writes one JSON object per line, fields go in a "fields"
+// object so they can't clash with time, level or msg
+//
+//	{"time":"2009-11-10T23:00:00Z","level":"ERROR","msg":"upload failed","fields":{"file":"a.txt","attempt":3}}
+//
+// a field value json can't encode is written as its fmt %v string
+type JSONEncoder struct{}
+
+func (JSONEncoder) Encode(e LogEntry) []byte {
+	var b bytes.Buffer
+	b.WriteString(`{"time":`)
+	writeJSON(&b, e.Time.Format(time.RFC3339Nano))
+	b.WriteString(`,"level":`)
+	writeJSON(&b, e.Level.String())
+	b.WriteString(`,"msg":`)
+	writeJSON(&b, e.Message)
+	if len(e.Fields) != 0 {
+		// encoding a map would sort the keys, so write the
+		// object by hand to keep them in the order they were given
+		b.WriteString(`,"fields":{`)
+		for i, f := range e.Fields {
+			if i != 0 {
+				b.WriteByte(',')
+			}
+			writeJSON(&b, f.Key)
+			b.WriteByte(':')
+			writeJSON(&b, f.Value)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+func writeJSON(b *bytes.Buffer, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	b.Write(data)
+}
+
+// LogfmtEncoder writes key=value pairs, quoting values that need it
+//
+//	time=2009-11-10T23:00:00Z level=ERROR msg="upload failed" file=a.txt attempt=3
+type LogfmtEncoder struct{}
+
+func (LogfmtEncoder) Encode(e LogEntry) []byte {
+	var b bytes.Buffer
+	writeLogfmtFields(&b, []Field{
+		{"time", e.Time.Format(time.RFC3339Nano)},
+		{"level", e.Level},
+		{"msg", e.Message},
+	})
+	if len(e.Fields) != 0 {
+		b.WriteByte(' ')
+		writeLogfmtFields(&b, e.Fields)
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+func writeLogfmtFields(b *bytes.Buffer, fields []Field) {
+	for i, f := range fields {
+		if i != 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(logfmtValue(f.Key))
+		b.WriteByte('=')
+		b.WriteString(logfmtValue(fmt.Sprint(f.Value)))
+	}
+}
+
+// logfmtValue quotes s if it is empty or has spaces,
+// quotes, equals signs or control characters in it
+func logfmtValue(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || unicode.IsControl(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
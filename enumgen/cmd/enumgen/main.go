@@ -0,0 +1,90 @@
+// Command enumgen is golearn gen enum on its own, for go:generate lines
+// in golearn's own packages: it imports nothing but package enumgen, so
+// it builds even while the package it's generating for doesn't
+//
+// Usage:
+//
+//	enumgen -type T [-trimprefix p] [-kind k] [-output file] [dir]
+//
+// like golearn gen enum it reads the package in dir, the current directory
+// by default, and writes <dir>/<type>_enum.go
+//
+//	//go:generate go run github.com/aljo242/golearn/enumgen/cmd/enumgen -type Region -trimprefix Region
+//
+// enumgen exits 0 when it wrote the file, 1 when it couldn't,
+// and 2 on bad usage
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aljo242/golearn/enumgen"
+)
+
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitUsage  = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("enumgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	typ := fs.String("type", "", "the integer type to name the constants of")
+	trim := fs.String("trimprefix", "", "cut this off the front of every constant's name")
+	kind := fs.String("kind", "auto", "auto, sequential or flags, auto makes flags of types declared with 1 << iota")
+	out := fs.String("output", "", "file to write, <dir>/<type>_enum.go by default, - for stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: enumgen -type T [-trimprefix p] [-kind k] [-output file] [dir]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	cfg := enumgen.Config{Dir: ".", Type: *typ, TrimPrefix: *trim}
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		cfg.Dir = fs.Arg(0)
+	default:
+		fs.Usage()
+		return exitUsage
+	}
+	kinds := map[string]enumgen.Kind{"auto": enumgen.Auto, "sequential": enumgen.Sequential, "flags": enumgen.Flags}
+	k, ok := kinds[*kind]
+	if cfg.Type == "" || !ok {
+		fs.Usage()
+		return exitUsage
+	}
+	cfg.Kind = k
+
+	src, err := enumgen.Generate(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "enumgen: %v\n", err)
+		return exitFailed
+	}
+	switch *out {
+	case "-":
+		_, err = stdout.Write(src)
+	case "":
+		err = ioutil.WriteFile(filepath.Join(cfg.Dir, strings.ToLower(cfg.Type)+"_enum.go"), src, 0644)
+	default:
+		err = ioutil.WriteFile(*out, src, 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "enumgen: %v\n", err)
+		return exitFailed
+	}
+	return exitOK
+}
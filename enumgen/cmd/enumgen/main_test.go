@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	src := "package days\n\ntype Day int\n\nconst (\n\tMon Day = iota\n\tTue\n)\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "days.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-type", "Day", dir}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("enumgen exited %d, stderr:\n%s", code, stderr.String())
+	}
+	gen, err := ioutil.ReadFile(filepath.Join(dir, "day_enum.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(gen), "func ParseDay(s string) (Day, error) {") {
+		t.Errorf("day_enum.go is missing ParseDay:\n%s", gen)
+	}
+
+	for _, tt := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{dir}, exitUsage},
+		{[]string{"-type", "Day", dir, dir}, exitUsage},
+		{[]string{"-type", "Day", "-kind", "bits", dir}, exitUsage},
+		{[]string{"-type", "Month", dir}, exitFailed},
+		{[]string{"-type", "Day", "-output", "-", dir}, exitOK},
+	} {
+		stdout.Reset()
+		if code := run(tt.args, &stdout, &stderr); code != tt.code {
+			t.Errorf("enumgen %v exited %d, want %d", tt.args, code, tt.code)
+		}
+	}
+	if !strings.HasPrefix(stdout.String(), "// Code generated by golearn gen enum") {
+		t.Errorf("enumgen -output - printed %q", stdout.String())
+	}
+}
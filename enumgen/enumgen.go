@@ -0,0 +1,446 @@
+// Package enumgen writes the methods that give iota constants names at
+// runtime, the code behind golearn gen enum and enumgen/cmd/enumgen
+//
+// given a named integer type and the constants of that type, like the
+// Constants lesson's
+//
+//	type Weekday int
+//
+//	const (
+//		Sunday Weekday = iota
+//		Monday
+//		...
+//	)
+//
+// Generate writes String, ParseWeekday, WeekdayValues, IsValid and
+// text and JSON marshalling for it
+// a type whose constants are declared with 1 << iota, like the lesson's
+// role flags, is a flag set instead: its String joins the names of the
+// bits that are set with |, "Read|Write", and its parser reads that back
+package enumgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Kind is the sort of enum to generate
+type Kind int
+
+const (
+	// Auto makes a flag set of types with a 1 << iota constant,
+	// and a sequential enum of the rest
+	Auto Kind = iota
+	// Sequential is an enum whose values are one constant each, 0, 1, 2...
+	Sequential
+	// Flags is a set of bits, each constant one of them (or a few together)
+	Flags
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Auto:
+		return "auto"
+	case Sequential:
+		return "sequential"
+	case Flags:
+		return "flags"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// ErrNoType is returned when the package has no integer type by that name
+var ErrNoType = errors.New("no such integer type")
+
+// ErrNoConstants is returned for a type without any constants to name
+var ErrNoConstants = errors.New("no constants")
+
+// Config is what to generate
+type Config struct {
+	// Dir is the package's directory, "." when empty
+	Dir string
+	// Type is the name of the integer type
+	Type string
+	// TrimPrefix is cut off the front of each constant's name, so
+	// WeekdaySunday can print as Sunday
+	TrimPrefix string
+	// Kind overrides guessing whether Type is an enum or a flag set
+	Kind Kind
+}
+
+// Generate reads the package in cfg.Dir and returns the formatted source
+// of a file giving cfg.Type's constants names
+func Generate(cfg Config) ([]byte, error) {
+	if cfg.Dir == "" {
+		cfg.Dir = "."
+	}
+	e, err := load(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	tmpl := sequentialTemplate
+	if e.Flags {
+		tmpl = flagsTemplate
+	}
+	if err := tmpl.Execute(&buf, e); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting the generated code: %w", err)
+	}
+	return src, nil
+}
+
+// enum is everything the templates need to know about a type
+type enum struct {
+	Package  string
+	Type     string
+	Flags    bool
+	Unsigned bool
+	Values   []value // one per distinct value, in declaration order
+	Zero     *value  // the constant that is 0, if there is one
+	Bits     []value // a flag set's single bit constants
+}
+
+// value is one constant, or the first of several with the same value
+type value struct {
+	Const   string   // the Go name
+	Name    string   // what String prints
+	Aliases []string // names of the later constants with the same value
+}
+
+// Names is every name Parse accepts for v, lowercased
+func (v value) Names() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, n := range append([]string{v.Name}, v.Aliases...) {
+		if n = strings.ToLower(n); !seen[n] {
+			seen[n] = true
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+func load(cfg Config) (*enum, error) {
+	bp, err := build.ImportDir(cfg.Dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(cfg.Dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	// the package's imports don't have to type check for its constants to,
+	// so errors are left to the compiler
+	conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+	pkg, _ := conf.Check(bp.ImportPath, fset, files, nil)
+
+	obj, ok := pkg.Scope().Lookup(cfg.Type).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w %s", bp.Name, ErrNoType, cfg.Type)
+	}
+	b, ok := obj.Type().Underlying().(*types.Basic)
+	if !ok || b.Info()&types.IsInteger == 0 {
+		return nil, fmt.Errorf("%s: %w %s, it is %s", bp.Name, ErrNoType, cfg.Type, obj.Type().Underlying())
+	}
+
+	var consts []*types.Const
+	for _, name := range pkg.Scope().Names() {
+		if c, ok := pkg.Scope().Lookup(name).(*types.Const); ok && types.Identical(c.Type(), obj.Type()) {
+			consts = append(consts, c)
+		}
+	}
+	if len(consts) == 0 {
+		return nil, fmt.Errorf("%s: %w of type %s", bp.Name, ErrNoConstants, cfg.Type)
+	}
+	sort.Slice(consts, func(i, j int) bool { return consts[i].Pos() < consts[j].Pos() })
+
+	e := &enum{Package: bp.Name, Type: cfg.Type, Unsigned: b.Info()&types.IsUnsigned != 0}
+	switch cfg.Kind {
+	case Auto:
+		e.Flags = shiftsIota(files, consts)
+	case Flags:
+		e.Flags = true
+	}
+
+	seen := make(map[string]int) // exact value -> index in e.Values
+	for _, c := range consts {
+		name := strings.TrimPrefix(c.Name(), cfg.TrimPrefix)
+		if name == "" {
+			name = c.Name()
+		}
+		key := c.Val().ExactString()
+		if i, ok := seen[key]; ok {
+			e.Values[i].Aliases = append(e.Values[i].Aliases, name)
+			continue
+		}
+		seen[key] = len(e.Values)
+		e.Values = append(e.Values, value{Const: c.Name(), Name: name})
+	}
+	for _, c := range consts {
+		v := &e.Values[seen[c.Val().ExactString()]]
+		if v.Const != c.Name() {
+			continue // an alias, already counted
+		}
+		switch {
+		case constant.Sign(c.Val()) == 0:
+			e.Zero = v
+		case e.Flags && isBit(c.Val()):
+			e.Bits = append(e.Bits, *v)
+		}
+	}
+	// Parse ignores case, so two values can't have names that only differ by it
+	owner := make(map[string]string)
+	for _, v := range e.Values {
+		for _, n := range v.Names() {
+			if other, ok := owner[n]; ok {
+				return nil, fmt.Errorf("%s: %s and %s would both parse from %q", bp.Name, other, v.Const, n)
+			}
+			owner[n] = v.Const
+		}
+	}
+	if e.Flags && len(e.Bits) == 0 {
+		return nil, fmt.Errorf("%s: %s is a flag set with no single bit constants", bp.Name, cfg.Type)
+	}
+	return e, nil
+}
+
+// isBit reports whether v is a power of two, one bit of a flag set
+func isBit(v constant.Value) bool {
+	if constant.Sign(v) <= 0 {
+		return false
+	}
+	less := constant.BinaryOp(v, token.SUB, constant.MakeInt64(1))
+	return constant.Sign(constant.BinaryOp(v, token.AND, less)) == 0
+}
+
+// shiftsIota reports whether any of the const declarations holding
+// consts shifts by iota, the way flag sets are declared
+func shiftsIota(files []*ast.File, consts []*types.Const) bool {
+	ours := make(map[token.Pos]bool)
+	for _, c := range consts {
+		ours[c.Pos()] = true
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST || !declares(gd, ours) {
+				continue
+			}
+			found := false
+			ast.Inspect(gd, func(n ast.Node) bool {
+				if b, ok := n.(*ast.BinaryExpr); ok && b.Op == token.SHL && mentionsIota(b.Y) {
+					found = true
+				}
+				return !found
+			})
+			if found {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func declares(gd *ast.GenDecl, ours map[token.Pos]bool) bool {
+	for _, spec := range gd.Specs {
+		for _, name := range spec.(*ast.ValueSpec).Names {
+			if ours[name.Pos()] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func mentionsIota(x ast.Expr) bool {
+	found := false
+	ast.Inspect(x, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == "iota" {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+var funcs = template.FuncMap{"quote": strconv.Quote}
+
+const header = `// Code generated by golearn gen enum; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// {{.Type}}Values returns every {{.Type}} constant, in declaration order
+func {{.Type}}Values() []{{.Type}} {
+	return []{{.Type}}{ {{- range .Values}}{{.Const}}, {{end -}} }
+}
+
+// MarshalText writes x the way String does
+func (x {{.Type}}) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText reads x with Parse{{.Type}}
+func (x *{{.Type}}) UnmarshalText(text []byte) error {
+	v, err := Parse{{.Type}}(string(text))
+	if err != nil {
+		return err
+	}
+	*x = v
+	return nil
+}
+
+// MarshalJSON writes x as a JSON string, the way String does
+func (x {{.Type}}) MarshalJSON() ([]byte, error) {
+	return json.Marshal(x.String())
+}
+
+// UnmarshalJSON reads x from a JSON string with Parse{{.Type}},
+// or from a JSON number as long as it IsValid
+func (x *{{.Type}}) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return x.UnmarshalText([]byte(s))
+	}
+	n, err := strconv.Parse{{if .Unsigned}}Uint{{else}}Int{{end}}(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("{{.Type}} should be a string or a number, not %s", data)
+	}
+	v := {{.Type}}(n)
+	if {{if .Unsigned}}uint64{{else}}int64{{end}}(v) != n || !v.IsValid() {
+		return fmt.Errorf("%d is not a valid {{.Type}}", n)
+	}
+	*x = v
+	return nil
+}
+`
+
+var sequentialTemplate = template.Must(template.New("sequential").Funcs(funcs).Parse(header + `
+// String returns x's constant name, or {{.Type}}(n) for a value without one
+func (x {{.Type}}) String() string {
+	switch x {
+	{{- range .Values}}
+	case {{.Const}}:
+		return {{quote .Name}}
+	{{- end}}
+	}
+	return "{{.Type}}(" + {{if .Unsigned}}strconv.FormatUint(uint64(x), 10){{else}}strconv.FormatInt(int64(x), 10){{end}} + ")"
+}
+
+// IsValid reports whether x is one of the {{.Type}} constants
+func (x {{.Type}}) IsValid() bool {
+	switch x {
+	case {{range $i, $v := .Values}}{{if $i}}, {{end}}{{$v.Const}}{{end}}:
+		return true
+	}
+	return false
+}
+
+// Parse{{.Type}} finds the {{.Type}} named s, ignoring case
+func Parse{{.Type}}(s string) ({{.Type}}, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	{{- range .Values}}
+	case {{range $i, $n := .Names}}{{if $i}}, {{end}}{{quote $n}}{{end}}:
+		return {{.Const}}, nil
+	{{- end}}
+	}
+	var zero {{.Type}}
+	return zero, fmt.Errorf("%q is not a {{.Type}}", s)
+}
+`))
+
+var flagsTemplate = template.Must(template.New("flags").Funcs(funcs).Parse(header + `
+// _{{.Type}}_bits is every single bit {{.Type}} with its name, lowest first
+var _{{.Type}}_bits = [...]struct {
+	bit  {{.Type}}
+	name string
+}{
+	{{- range .Bits}}
+	{ {{- .Const}}, {{quote .Name -}} },
+	{{- end}}
+}
+
+// String joins the names of the flags set in x with |, bits without
+// a name are written in hex{{if .Zero}}, and no flags at all is {{.Zero.Name}}{{end}}
+func (x {{.Type}}) String() string {
+	if x == 0 {
+		return {{if .Zero}}{{quote .Zero.Name}}{{else}}"0"{{end}}
+	}
+	var names []string
+	for _, b := range _{{.Type}}_bits {
+		if x&b.bit == b.bit {
+			names = append(names, b.name)
+			x &^= b.bit
+		}
+	}
+	if x != 0 {
+		names = append(names, "0x"+strconv.FormatUint(uint64(x), 16))
+	}
+	return strings.Join(names, "|")
+}
+
+// IsValid reports whether x only has bits that are {{.Type}} constants
+func (x {{.Type}}) IsValid() bool {
+	const all = {{range $i, $v := .Values}}{{if $i}} | {{end}}{{$v.Const}}{{end}}
+	return x&^all == 0
+}
+
+// Parse{{.Type}} reads flags the way String writes them: names joined by |,
+// in any order and any case, and hex numbers for bits without a name
+func Parse{{.Type}} (s string) ({{.Type}}, error) {
+	var x {{.Type}}
+	if strings.TrimSpace(s) == "" {
+		return x, nil
+	}
+	for _, part := range strings.Split(s, "|") {
+		switch name := strings.ToLower(strings.TrimSpace(part)); name {
+		{{- range .Values}}
+		case {{range $i, $n := .Names}}{{if $i}}, {{end}}{{quote $n}}{{end}}:
+			x |= {{.Const}}
+		{{- end}}
+		default:
+			if !strings.HasPrefix(name, "0x") {
+				return 0, fmt.Errorf("%q is not a {{.Type}} flag, in %q", part, s)
+			}
+			n, err := strconv.ParseUint(name[2:], 16, 64)
+			if err != nil || uint64({{.Type}}(n)) != n {
+				return 0, fmt.Errorf("%q is not a {{.Type}} flag, in %q", part, s)
+			}
+			x |= {{.Type}}(n)
+		}
+	}
+	return x, nil
+}
+`))
@@ -0,0 +1,213 @@
+package enumgen
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateKinds(t *testing.T) {
+	tests := []struct {
+		cfg      Config
+		expected []string
+	}{
+		{Config{Dir: "testdata/weekday", Type: "Weekday"}, []string{
+			"package weekday\n",
+			"func WeekdayValues() []Weekday {\n\treturn []Weekday{Sunday, Monday, Tuesday, Wednesday, Thursday, Friday, Saturday}\n}",
+			`case "saturday", "caturday":`,
+			"func (x Weekday) IsValid() bool {\n\tswitch x {",
+		}},
+		{Config{Dir: "testdata/perm", Type: "Perm"}, []string{
+			"var _Perm_bits = ",
+			`{Read, "Read"},`,
+			`return "None"`,
+			"const all = Read | Write | Exec | ReadWrite | None",
+		}},
+		{Config{Dir: "testdata/perm", Type: "Perm", Kind: Sequential}, []string{
+			"func (x Perm) String() string {\n\tswitch x {",
+		}},
+		{Config{Dir: "testdata/color", Type: "Color", TrimPrefix: "Color"}, []string{
+			`case ColorRed:` + "\n\t\t" + `return "Red"`,
+			"strconv.FormatUint(uint64(x), 10)",
+		}},
+	}
+	for _, tt := range tests {
+		src, err := Generate(tt.cfg)
+		if err != nil {
+			t.Errorf("Generate(%+v) error = %v", tt.cfg, err)
+			continue
+		}
+		if !strings.HasPrefix(string(src), "// Code generated by golearn gen enum; DO NOT EDIT.\n") {
+			t.Errorf("Generate(%+v) is missing the generated code header", tt.cfg)
+		}
+		for _, want := range tt.expected {
+			if !strings.Contains(string(src), want) {
+				t.Errorf("Generate(%+v) is missing %q, got:\n%s", tt.cfg, want, src)
+			}
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	dir := t.TempDir()
+	src := `package bad
+
+type Name string
+
+type Empty int
+
+type Clash int
+
+const (
+	Up Clash = iota
+	UP
+)
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "bad.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		typ  string
+		kind error // nil for any error
+	}{
+		{"Missing", ErrNoType},
+		{"Name", ErrNoType},
+		{"Empty", ErrNoConstants},
+		{"Clash", nil},
+	}
+	for _, tt := range tests {
+		_, err := Generate(Config{Dir: dir, Type: tt.typ})
+		if err == nil || (tt.kind != nil && !errors.Is(err, tt.kind)) {
+			t.Errorf("Generate(%s) error = %v, want %v", tt.typ, err, tt.kind)
+		}
+	}
+	if _, err := Generate(Config{Dir: filepath.Join(dir, "nope"), Type: "Clash"}); err == nil {
+		t.Error("Generate of a missing directory succeeded")
+	}
+}
+
+// drivers exercise each fixture's generated code, and print what it did
+var drivers = map[string]struct{ main, expected string }{
+	"weekday": {`
+	fmt.Println(weekday.Monday, weekday.Weekday(9), weekday.WeekdayValues())
+	d, err := weekday.ParseWeekday(" CATURDAY ")
+	fmt.Println(d, err, d.IsValid(), weekday.Weekday(7).IsValid())
+	_, err = weekday.ParseWeekday("Someday")
+	fmt.Println(err)
+	data, _ := json.Marshal(map[string]weekday.Weekday{"day": weekday.Friday})
+	fmt.Println(string(data))
+	var days []weekday.Weekday
+	err = json.Unmarshal([]byte(` + "`" + `["tuesday", 3]` + "`" + `), &days)
+	fmt.Println(days, err)
+	err = json.Unmarshal([]byte("[7]"), &days)
+	fmt.Println(err)
+`, `Monday Weekday(9) [Sunday Monday Tuesday Wednesday Thursday Friday Saturday]
+Saturday <nil> true false
+"Someday" is not a Weekday
+{"day":"Friday"}
+[Tuesday Wednesday] <nil>
+7 is not a valid Weekday
+`},
+	"perm": {`
+	fmt.Println(perm.None, perm.Read|perm.Exec, perm.ReadWrite, perm.Perm(0x41))
+	p, err := perm.ParsePerm("readwrite | EXEC")
+	fmt.Println(p, err, p.IsValid(), perm.Perm(0x41).IsValid())
+	p, err = perm.ParsePerm("read|0x40")
+	fmt.Println(uint8(p), err)
+	_, err = perm.ParsePerm("read|delete")
+	fmt.Println(err)
+	data, _ := json.Marshal(perm.Write | perm.Exec)
+	fmt.Println(string(data))
+	err = json.Unmarshal([]byte("5"), &p)
+	fmt.Println(p, err)
+`, `None Read|Exec Read|Write Read|0x40
+Read|Write|Exec <nil> true false
+65 <nil>
+"delete" is not a Perm flag, in "read|delete"
+"Write|Exec"
+Read|Exec <nil>
+`},
+	"color": {`
+	fmt.Println(color.ColorRed, color.Color(0), color.ColorValues())
+	c, err := color.ParseColor("blue")
+	fmt.Println(c, err)
+	var zero color.Color
+	text, _ := zero.MarshalText()
+	fmt.Println(zero.IsValid(), string(text))
+`, `Red Color(0) [Red Green Blue]
+Blue <nil>
+false Color(0)
+`},
+}
+
+// TestGeneratedCodeCompiles generates each fixture's file next to a copy
+// of it and runs a program using what was generated
+func TestGeneratedCodeCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds with the go command")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go command to build with")
+	}
+
+	dir := t.TempDir()
+	write := func(name, src string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module fixture\n\ngo 1.15\n")
+
+	fixtures := []Config{
+		{Dir: "testdata/weekday", Type: "Weekday"},
+		{Dir: "testdata/perm", Type: "Perm"},
+		{Dir: "testdata/color", Type: "Color", TrimPrefix: "Color"},
+	}
+	var main, expected strings.Builder
+	main.WriteString("package main\n\nimport (\n\t\"encoding/json\"\n\t\"fmt\"\n\n")
+	for _, cfg := range fixtures {
+		name := filepath.Base(cfg.Dir)
+		src, err := ioutil.ReadFile(filepath.Join(cfg.Dir, name+".go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gen, err := Generate(cfg)
+		if err != nil {
+			t.Fatalf("Generate(%+v) error = %v", cfg, err)
+		}
+		write(filepath.Join(name, name+".go"), string(src))
+		write(filepath.Join(name, strings.ToLower(cfg.Type)+"_enum.go"), string(gen))
+		fmt.Fprintf(&main, "\t%q\n", "fixture/"+name)
+	}
+	main.WriteString(")\n\nfunc main() {")
+	for _, cfg := range fixtures {
+		d := drivers[filepath.Base(cfg.Dir)]
+		main.WriteString("\n\t{" + d.main + "\t}\n") // a block each, so their variables don't clash
+		expected.WriteString(d.expected)
+	}
+	main.WriteString("}\n")
+	write("main.go", main.String())
+
+	cmd := exec.Command(goCmd, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off", "GOTOOLCHAIN=local")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run failed: %v\n%s\nmain.go:\n%s", err, out, main.String())
+	}
+	if string(out) != expected.String() {
+		t.Errorf("the generated code printed\n%s\nwant\n%s", out, expected.String())
+	}
+}
@@ -0,0 +1,14 @@
+// Package color is an enum with prefixed names that starts at 1,
+// so the zero Color isn't one of them
+package color
+
+//go:generate go run github.com/aljo242/golearn/enumgen/cmd/enumgen -type Color -trimprefix Color
+
+// Color is a paint color
+type Color uint
+
+const (
+	ColorRed Color = iota + 1
+	ColorGreen
+	ColorBlue
+)
@@ -0,0 +1,18 @@
+// Package perm is a flag set, like the Constants lesson's roles
+package perm
+
+//go:generate go run github.com/aljo242/golearn/enumgen/cmd/enumgen -type Perm
+
+// Perm is what a user may do with a file
+type Perm uint8
+
+const (
+	Read Perm = 1 << iota
+	Write
+	Exec
+
+	// ReadWrite is two flags at once, it parses but prints as read|write
+	ReadWrite = Read | Write
+	// None is no flags at all
+	None Perm = 0
+)
@@ -0,0 +1,23 @@
+// Package weekday is a sequential enum, like the Constants lesson's c, d, e
+package weekday
+
+//go:generate go run github.com/aljo242/golearn/enumgen/cmd/enumgen -type Weekday
+
+// Weekday is a day of the week
+type Weekday int
+
+const (
+	Sunday Weekday = iota
+	Monday
+	Tuesday
+	Wednesday
+	Thursday
+	Friday
+	Saturday
+
+	// Caturday is Saturday by another name, it parses but never prints
+	Caturday = Saturday
+)
+
+// Weekend isn't a Weekday, so it doesn't get a name
+const Weekend = 2
@@ -0,0 +1,186 @@
+package golearn
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProcessInfo is what the OS lesson reports about the running process
+type ProcessInfo struct {
+	Pid        int
+	Ppid       int
+	Uid        int
+	Executable string
+	PageSize   int
+	Environ    []string // KEY=value pairs, like os.Environ()
+}
+
+// Getenv looks key up in Environ the way os.Getenv does
+func (p ProcessInfo) Getenv(key string) string {
+	for _, kv := range p.Environ {
+		if strings.HasPrefix(kv, key+"=") {
+			return kv[len(key)+1:]
+		}
+	}
+	return ""
+}
+
+// Env is everything a lesson may ask about the machine it is running on
+// lessons consult an Env instead of calling os, time or %p directly
+// so the same run can produce byte-identical output anywhere
+// a nil Env, or a nil field, falls back to the real system
+type Env struct {
+	// Process reports pid, ppid, uid, executable path, page size and environment
+	Process func() (ProcessInfo, error)
+	// Hostname reports the machine's host name
+	Hostname func() (string, error)
+	// Now is the clock used for timestamps
+	Now func() time.Time
+	// Getwd reports the current working directory
+	Getwd func() (string, error)
+	// Addr formats a pointer the way %p would
+	Addr func(p interface{}) string
+	// Fetcher does the HTTP GETs, http.DefaultClient when nil
+	Fetcher Fetcher
+	// RobotsURL is the robots.txt DeferPanicRecover fetches,
+	// DefaultRobotsURL when empty
+	RobotsURL string
+}
+
+// SystemEnv is the Env of the real machine, which is what a nil Env means
+func SystemEnv() *Env {
+	return &Env{
+		Process:  systemProcess,
+		Hostname: os.Hostname,
+		Now:      time.Now,
+		Getwd:    os.Getwd,
+		Addr:     func(p interface{}) string { return fmt.Sprintf("%p", p) },
+		Fetcher:  http.DefaultClient,
+	}
+}
+
+func systemProcess() (ProcessInfo, error) {
+	info := ProcessInfo{
+		Pid:      os.Getpid(),
+		Ppid:     os.Getppid(),
+		Uid:      os.Getuid(),
+		PageSize: os.Getpagesize(),
+		Environ:  os.Environ(),
+	}
+	exe, err := os.Executable()
+	info.Executable = exe
+	return info, err
+}
+
+// the values a DeterministicEnv reports
+// the clock is stopped at the same instant as the Go playground's
+var (
+	deterministicProcess = ProcessInfo{
+		Pid:        4242,
+		Ppid:       4241,
+		Uid:        1000,
+		Executable: "/usr/local/bin/golearn",
+		PageSize:   4096,
+		Environ:    []string{"HOME=/home/gopher", "USER=gopher"},
+	}
+	deterministicHostname = "golearn"
+	deterministicTime     = time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	deterministicWd       = "/home/gopher/golearn"
+)
+
+// DeterministicEnv is an Env that reports the same process, host, time
+// and working dir on every machine, for CI and recorded tutorials
+// pointers get made up addresses, 8 bytes apart in the order they are
+// first formatted, and the same pointer always formats the same way
+// each DeterministicEnv numbers its pointers separately, so use a new
+// one per run to get the same addresses every time
+func DeterministicEnv() *Env {
+	var (
+		mu    sync.Mutex
+		addrs = make(map[uintptr]string)
+	)
+	return &Env{
+		Process: func() (ProcessInfo, error) {
+			info := deterministicProcess
+			info.Environ = append([]string(nil), deterministicProcess.Environ...)
+			return info, nil
+		},
+		Hostname: func() (string, error) { return deterministicHostname, nil },
+		Now:      func() time.Time { return deterministicTime },
+		Getwd:    func() (string, error) { return deterministicWd, nil },
+		Addr: func(p interface{}) string {
+			ptr := reflect.ValueOf(p).Pointer()
+			mu.Lock()
+			defer mu.Unlock()
+			if a, ok := addrs[ptr]; ok {
+				return a
+			}
+			a := fmt.Sprintf("%#x", 0xc000010000+8*len(addrs))
+			addrs[ptr] = a
+			return a
+		},
+	}
+}
+
+// the accessors below fill in for a nil Env or nil field
+
+func (e *Env) process() (ProcessInfo, error) {
+	if e == nil || e.Process == nil {
+		return systemProcess()
+	}
+	return e.Process()
+}
+
+func (e *Env) hostname() (string, error) {
+	if e == nil || e.Hostname == nil {
+		return os.Hostname()
+	}
+	return e.Hostname()
+}
+
+func (e *Env) now() time.Time {
+	if e == nil || e.Now == nil {
+		return time.Now()
+	}
+	return e.Now()
+}
+
+func (e *Env) getwd() (string, error) {
+	if e == nil || e.Getwd == nil {
+		return os.Getwd()
+	}
+	return e.Getwd()
+}
+
+func (e *Env) addr(p interface{}) string {
+	if e == nil || e.Addr == nil {
+		return fmt.Sprintf("%p", p)
+	}
+	return e.Addr(p)
+}
+
+func (e *Env) fetcher() Fetcher {
+	if e == nil || e.Fetcher == nil {
+		return http.DefaultClient
+	}
+	return e.Fetcher
+}
+
+func (e *Env) robotsURL() string {
+	if e == nil || e.RobotsURL == "" {
+		return DefaultRobotsURL
+	}
+	return e.RobotsURL
+}
+
+// logf prints a line the way the standard logger does,
+// but with its timestamp coming from the Env's clock
+func (e *Env) logf(w io.Writer, format string, args ...interface{}) {
+	fmt.Fprintf(w, "%s %s\n", e.now().Format("2006/01/02 15:04:05"), fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,94 @@
+package golearn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// the kinds of failure a lesson can report, match them with errors.Is
+var (
+	// ErrFetch means an HTTP fetch failed, e.g. DeferPanicRecover's robots.txt
+	ErrFetch = errors.New("fetch failed")
+	// ErrFilesystem means creating, walking or moving around files failed
+	ErrFilesystem = errors.New("filesystem operation failed")
+	// ErrProcess means the process, host or environment could not be queried
+	ErrProcess = errors.New("process info unavailable")
+	// ErrOutput means writing the lesson's own output failed
+	ErrOutput = errors.New("writing output failed")
+	// ErrPanic means a lesson panicked and Run recovered it
+	ErrPanic = errors.New("lesson panicked")
+	// ErrCanceled means the lesson's context was done before it finished
+	ErrCanceled = errors.New("lesson canceled")
+	// ErrTimeout means RunWithTimeout gave up on a lesson, see TimeoutError
+	ErrTimeout = errors.New("lesson timed out")
+	// ErrLeak means a lesson returned but left goroutines running, see LeakError
+	ErrLeak = errors.New("lesson leaked goroutines")
+)
+
+// LessonError is a failure inside a lesson
+// errors.Is matches it against its Kind, one of the Err* values above,
+// while errors.As and errors.Unwrap reach the underlying Err
+type LessonError struct {
+	Lesson string // name of the lesson that failed
+	Kind   error  // which sort of failure, e.g. ErrFetch
+	Op     string // what the lesson was doing, e.g. "reading robots.txt"
+	Err    error  // the underlying error, may be nil
+}
+
+func (e *LessonError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%s: %s: %v", e.Lesson, e.Op, e.Kind)
+	}
+	return fmt.Sprintf("%s: %s: %v", e.Lesson, e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *LessonError) Unwrap() error { return e.Err }
+
+// Is reports whether target is this error's Kind
+func (e *LessonError) Is(target error) bool { return target == e.Kind }
+
+func lessonError(lesson string, kind error, op string, err error) error {
+	return &LessonError{Lesson: lesson, Kind: kind, Op: op, Err: err}
+}
+
+// TimeoutError is what RunWithTimeout returns for a lesson that ran past
+// its timeout, errors.Is matches it against ErrTimeout and
+// context.DeadlineExceeded
+type TimeoutError struct {
+	Lesson  string        // name of the lesson that timed out
+	Timeout time.Duration // how long it was given
+	// Goroutines holds the stack of every goroutine the lesson
+	// still had running when it was given up on
+	Goroutines []string
+	// Err is what the lesson returned once it noticed its context was done,
+	// nil if it never returned
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s: timed out after %v with %d goroutines still running", e.Lesson, e.Timeout, len(e.Goroutines))
+}
+
+// Unwrap returns context.DeadlineExceeded
+func (e *TimeoutError) Unwrap() error { return context.DeadlineExceeded }
+
+// Is reports whether target is ErrTimeout
+func (e *TimeoutError) Is(target error) bool { return target == ErrTimeout }
+
+// LeakError is what RunWithTimeout returns for a lesson that finished
+// but left goroutines behind, errors.Is matches it against ErrLeak
+type LeakError struct {
+	Lesson string // name of the lesson that leaked
+	// Goroutines holds the stack of every goroutine still running
+	Goroutines []string
+}
+
+func (e *LeakError) Error() string {
+	return fmt.Sprintf("%s: returned with %d goroutines still running", e.Lesson, len(e.Goroutines))
+}
+
+// Is reports whether target is ErrLeak
+func (e *LeakError) Is(target error) bool { return target == ErrLeak }
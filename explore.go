@@ -0,0 +1,263 @@
+package golearn
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// MapsAndStructs looks up one field's tag with reflect, the explorer here
+// walks a whole type the same way: every field, its kind and size, whether
+// it is embedded or exported, its tag, what methods the type has and which
+// of this package's interfaces it satisfies
+
+// Explorer prints type trees, see Explore
+type Explorer struct {
+	// MaxDepth is how many levels of fields to expand, 0 for all of them
+	MaxDepth int
+	// Foreign expands named types from other packages too, sync.Mutex and
+	// the like, rather than stopping at them
+	Foreign bool
+}
+
+// Explore prints v's type tree to w with the default Explorer
+func Explore(w io.Writer, v interface{}) error {
+	return Explorer{}.Explore(w, v)
+}
+
+// Explore prints the type tree of v, see ExploreType
+func (e Explorer) Explore(w io.Writer, v interface{}) error {
+	return e.ExploreType(w, reflect.TypeOf(v))
+}
+
+// ExploreType prints t to w (os.Stdout when w is nil): one line per field,
+// nested under the field it belongs to, then the method sets of t and *t
+// and which of Writer, Closer, WriterCloser and Incrementer they satisfy
+func (e Explorer) ExploreType(w io.Writer, t reflect.Type) error {
+	if t == nil {
+		return errors.New("explore: nil has no type")
+	}
+	x := explorer{Explorer: e, pkg: pkgOf(t), expanding: make(map[reflect.Type]bool)}
+	fmt.Fprintf(&x.out, "%s: %s\n", t, describe(t))
+	x.expand(t, 1)
+
+	fmt.Fprintln(&x.out)
+	x.methods(t)
+	if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface {
+		x.methods(reflect.PtrTo(t))
+	}
+
+	fmt.Fprintln(&x.out)
+	x.implements(t)
+	_, err := output(w).Write([]byte(x.out.String()))
+	return err
+}
+
+// explorer is one ExploreType call in progress
+type explorer struct {
+	Explorer
+	out       strings.Builder
+	pkg       string                // t's package, the one expanded without Foreign
+	expanding map[reflect.Type]bool // the types between the root and here
+}
+
+// expand prints the children of t, depth levels down
+func (x *explorer) expand(t reflect.Type, depth int) {
+	if x.MaxDepth > 0 && depth > x.MaxDepth {
+		return
+	}
+	if t.Name() != "" && t.PkgPath() != x.pkg && !x.Foreign {
+		return
+	}
+	// a type can only contain itself through a name, type L []L as much as
+	// a struct with a *Node in it, so any type already on the way down
+	// from the root is recursion and has been printed already
+	if x.expanding[t] {
+		return
+	}
+	x.expanding[t] = true
+	defer delete(x.expanding, t)
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			line := fmt.Sprintf("%s %s: %s, offset %d", f.Name, f.Type, describe(f.Type), f.Offset)
+			if f.Anonymous {
+				line += ", embedded"
+			}
+			if f.PkgPath == "" {
+				line += ", exported"
+			} else {
+				line += ", unexported"
+			}
+			if f.Tag != "" {
+				line += fmt.Sprintf(", tag `%s`", f.Tag)
+			}
+			if x.expanding[f.Type] {
+				line += ", recursive"
+			}
+			x.line(depth, line)
+			x.expand(f.Type, depth+1)
+		}
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Chan:
+		x.child(depth, "elem", t.Elem())
+	case reflect.Map:
+		x.child(depth, "key", t.Key())
+		x.child(depth, "elem", t.Elem())
+	case reflect.Interface:
+		for i := 0; i < t.NumMethod(); i++ {
+			m := t.Method(i)
+			x.line(depth, fmt.Sprintf("method %s%s", m.Name, signature(m.Type, 0)))
+		}
+	}
+}
+
+func (x *explorer) child(depth int, name string, t reflect.Type) {
+	line := fmt.Sprintf("%s %s: %s", name, t, describe(t))
+	if x.expanding[t] {
+		line += ", recursive"
+	}
+	x.line(depth, line)
+	x.expand(t, depth+1)
+}
+
+func (x *explorer) line(depth int, s string) {
+	fmt.Fprintf(&x.out, "%s%s\n", strings.Repeat("  ", depth), s)
+}
+
+// methods prints t's method set, the methods callable on a t
+func (x *explorer) methods(t reflect.Type) {
+	if t.NumMethod() == 0 {
+		fmt.Fprintf(&x.out, "method set of %s: none\n", t)
+		return
+	}
+	fmt.Fprintf(&x.out, "method set of %s:\n", t)
+	skip := 1 // a concrete type's methods take the receiver first
+	if t.Kind() == reflect.Interface {
+		skip = 0
+	}
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		x.line(1, m.Name+signature(m.Type, skip))
+	}
+}
+
+// the interfaces ExploreType checks for
+var exploredInterfaces = []struct {
+	name string
+	t    reflect.Type
+}{
+	{"Writer", reflect.TypeOf((*Writer)(nil)).Elem()},
+	{"Closer", reflect.TypeOf((*Closer)(nil)).Elem()},
+	{"WriterCloser", reflect.TypeOf((*WriterCloser)(nil)).Elem()},
+	{"Incrementer", reflect.TypeOf((*Incrementer)(nil)).Elem()},
+}
+
+// implements prints which of the package's interfaces t satisfies,
+// and whether it takes a pointer to do it
+func (x *explorer) implements(t reflect.Type) {
+	fmt.Fprintln(&x.out, "implements:")
+	tw := tabwriter.NewWriter(&x.out, 0, 8, 2, ' ', 0)
+	for _, i := range exploredInterfaces {
+		how := "no"
+		switch {
+		case t.Implements(i.t) && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface):
+			how = "yes"
+		case t.Implements(i.t):
+			how = "yes, value and pointer"
+		case t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface && reflect.PtrTo(t).Implements(i.t):
+			how = "pointer only"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", i.name, how)
+	}
+	tw.Flush()
+}
+
+// describe is t's kind and size, e.g. "struct, 40 bytes"
+func describe(t reflect.Type) string {
+	unit := "bytes"
+	if t.Size() == 1 {
+		unit = "byte"
+	}
+	return fmt.Sprintf("%s, %d %s", t.Kind(), t.Size(), unit)
+}
+
+// signature is a func type written the way it is declared,
+// e.g. "([]uint8) (int, error)", leaving out the first skip parameters
+func signature(t reflect.Type, skip int) string {
+	var in []string
+	for i := skip; i < t.NumIn(); i++ {
+		p := t.In(i).String()
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			p = "..." + t.In(i).Elem().String()
+		}
+		in = append(in, p)
+	}
+	var out []string
+	for i := 0; i < t.NumOut(); i++ {
+		out = append(out, t.Out(i).String())
+	}
+
+	s := "(" + strings.Join(in, ", ") + ")"
+	switch len(out) {
+	case 0:
+	case 1:
+		s += " " + out[0]
+	default:
+		s += " (" + strings.Join(out, ", ") + ")"
+	}
+	return s
+}
+
+// pkgOf is the package t, or what t points to or holds, was declared in
+func pkgOf(t reflect.Type) string {
+	for t.Name() == "" {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Chan, reflect.Map:
+			t = t.Elem()
+		default:
+			return ""
+		}
+	}
+	return t.PkgPath()
+}
+
+// the types golearn inspect knows by name
+var inspectable = map[string]reflect.Type{}
+
+func init() {
+	for _, v := range []interface{}{
+		Animal{}, Bird{}, Doctor{}, IntCounter(0), Role(0),
+		(*Writer)(nil), (*Closer)(nil), (*WriterCloser)(nil), (*Incrementer)(nil),
+		ConsoleWriter{}, TCPWriter{}, FileWriter{}, BufferedWriterCloser{}, MultiWriter{},
+		Logger{}, LoggerConfig{}, LogEntry{}, LogQuery{},
+		Env{}, LessonError{},
+	} {
+		t := reflect.TypeOf(v)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem() // the interfaces, which only a pointer can hold
+		}
+		inspectable[strings.ToLower(t.Name())] = t
+	}
+}
+
+// InspectableTypes is the names of the package's types LookupType knows, sorted
+func InspectableTypes() []string {
+	names := make([]string, 0, len(inspectable))
+	for _, t := range inspectable {
+		names = append(names, t.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupType finds one of the package's types by name, ignoring case
+func LookupType(name string) (reflect.Type, bool) {
+	t, ok := inspectable[strings.ToLower(name)]
+	return t, ok
+}
@@ -0,0 +1,128 @@
+package golearn
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func explore(t *testing.T, e Explorer, v interface{}) string {
+	t.Helper()
+	var out strings.Builder
+	if err := e.Explore(&out, v); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestExplore(t *testing.T) {
+	got := explore(t, Explorer{}, Bird{})
+	for _, want := range []string{
+		"golearn.Bird: struct, ",
+		"\n  Animal golearn.Animal: struct, ",
+		", offset 0, embedded, exported\n",
+		"\n    Name string: string, ",
+		", exported, tag `required:\"true\" max:\"100\"`\n",
+		"\n  CanFly bool: bool, 1 byte, ",
+		"method set of golearn.Bird: none\n",
+		"method set of *golearn.Bird: none\n",
+		"  Writer        no\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Explore(Bird{}) is missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestExploreMethodsAndInterfaces(t *testing.T) {
+	tests := []struct {
+		v        interface{}
+		expected []string
+	}{
+		{IntCounter(0), []string{
+			"method set of golearn.IntCounter: none\n",
+			"method set of *golearn.IntCounter:\n  Increment() int\n",
+			"  Incrementer   pointer only\n",
+		}},
+		{ConsoleWriter{}, []string{
+			"method set of golearn.ConsoleWriter:\n  Write([]uint8) (int, error)\n",
+			"  Writer        yes, value and pointer\n",
+			"  WriterCloser  no\n",
+		}},
+		{&BufferedWriterCloser{}, []string{
+			"*golearn.BufferedWriterCloser: ptr, ",
+			"\n  elem golearn.BufferedWriterCloser: struct, ",
+			"\n    mu sync.Mutex: struct, ",
+			"  Flush() error\n",
+			"  WriterCloser  yes\n",
+		}},
+		{(*WriterCloser)(nil), []string{
+			"  elem golearn.WriterCloser: interface, ",
+			"    method Close() error\n",
+		}},
+		{(*Logger)(nil), []string{
+			"  Info(string, ...golearn.Field)\n",
+			"  Log(golearn.Level, string, ...golearn.Field) error\n",
+		}},
+	}
+	for _, tt := range tests {
+		got := explore(t, Explorer{}, tt.v)
+		for _, want := range tt.expected {
+			if !strings.Contains(got, want) {
+				t.Errorf("Explore(%T) is missing %q, got:\n%s", tt.v, want, got)
+			}
+		}
+	}
+}
+
+func TestExploreDepth(t *testing.T) {
+	// other packages' types stop the tree unless Foreign says otherwise
+	local := explore(t, Explorer{}, TCPWriter{})
+	foreign := explore(t, Explorer{Foreign: true}, TCPWriter{})
+	if !strings.Contains(local, "\n  mu sync.Mutex: struct, ") || !strings.Contains(local, "\n  conn *golearn.watchedConn") {
+		t.Errorf("Explore(TCPWriter{}) is missing fields:\n%s", local)
+	}
+	if strings.Count(foreign, "\n") <= strings.Count(local, "\n") {
+		t.Errorf("Explorer{Foreign: true} didn't expand sync.Mutex and friends:\n%s", foreign)
+	}
+	if got := explore(t, Explorer{MaxDepth: 1}, Bird{}); strings.Contains(got, "Name string") {
+		t.Errorf("Explorer{MaxDepth: 1} expanded the embedded Animal:\n%s", got)
+	}
+
+	type node struct {
+		Next     *node
+		Children []node
+	}
+	got := explore(t, Explorer{}, node{})
+	if !strings.Contains(got, "elem golearn.node: struct, ") || !strings.Contains(got, ", recursive") {
+		t.Errorf("Explore(node{}) didn't mark the recursion:\n%s", got)
+	}
+
+	// recursion through a slice or a map, with no struct in the way
+	type list []list
+	type tree map[string]tree
+	if got := explore(t, Explorer{}, list{}); !strings.Contains(got, fmt.Sprintf("\n  elem golearn.list: slice, %d bytes, recursive\n", unsafe.Sizeof(list{}))) {
+		t.Errorf("Explore(list{}) didn't mark the recursion:\n%s", got)
+	}
+	if got := explore(t, Explorer{}, tree{}); !strings.Contains(got, fmt.Sprintf("\n  elem golearn.tree: map, %d bytes, recursive\n", unsafe.Sizeof(tree{}))) {
+		t.Errorf("Explore(tree{}) didn't mark the recursion:\n%s", got)
+	}
+}
+
+func TestExploreNil(t *testing.T) {
+	if err := Explore(&strings.Builder{}, nil); err == nil {
+		t.Error("Explore(nil) succeeded")
+	}
+}
+
+func TestLookupType(t *testing.T) {
+	for _, name := range InspectableTypes() {
+		if typ, ok := LookupType(strings.ToLower(name)); !ok || typ.Name() != name {
+			t.Errorf("LookupType(%q) = %v, %v", strings.ToLower(name), typ, ok)
+		}
+	}
+	if _, ok := LookupType("NotAType"); ok {
+		t.Error("LookupType(NotAType) found something")
+	}
+}
@@ -0,0 +1,288 @@
+package golearn
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyncPolicy is when a FileWriter asks the OS to flush the file to disk
+type SyncPolicy int
+
+const (
+	// SyncNever leaves flushing to the OS, fastest but a crash can lose writes
+	SyncNever SyncPolicy = iota
+	// SyncEveryWrite fsyncs after every Write, slowest but safest
+	SyncEveryWrite
+	// SyncInterval fsyncs every SyncEvery from a goroutine of its own
+	SyncInterval
+)
+
+// DefaultSyncEvery is how often SyncInterval syncs when SyncEvery is 0
+const DefaultSyncEvery = time.Second
+
+// FileWriter is a Writer to a File
+// it opens Path for appending on the first Write, making any missing
+// parent directories, and when a write would take the file past MaxSize
+// bytes it rotates: the file becomes Path.1, the old Path.1 becomes
+// Path.2 and so on, keeping MaxBackups of them (or all of them when 0),
+// gzipped to Path.1.gz and so on when Compress is set
+// a FileWriter is safe to use from many goroutines at once,
+// and has to be closed when you are done with it
+type FileWriter struct {
+	Path       string
+	Perm       os.FileMode // for a new file, 0644 when 0
+	Sync       SyncPolicy
+	SyncEvery  time.Duration // how often SyncInterval syncs
+	MaxSize    int64         // bytes before rotating, 0 never rotates
+	MaxBackups int
+	Compress   bool
+
+	mu      sync.Mutex
+	f       *os.File
+	size    int64
+	closed  bool
+	stop    chan struct{} // closed by Close to stop the syncing goroutine
+	stopped chan struct{}
+	onSync  func() // if set, called after each interval sync
+}
+
+// NewFileWriter makes a FileWriter appending to path, which never syncs
+// or rotates until told to, it doesn't open the file until the first Write
+func NewFileWriter(path string) *FileWriter {
+	return &FileWriter{Path: path}
+}
+
+// Write appends data to the file, rotating first if data won't fit
+// a single write bigger than MaxSize gets a file to itself
+func (fw *FileWriter) Write(data []byte) (int, error) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.closed {
+		return 0, ErrWriterClosed
+	}
+	if fw.f == nil {
+		if err := fw.open(); err != nil {
+			return 0, err
+		}
+	}
+	if fw.MaxSize > 0 && fw.size > 0 && fw.size+int64(len(data)) > fw.MaxSize {
+		if err := fw.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := fw.f.Write(data)
+	fw.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	if fw.Sync == SyncEveryWrite {
+		return n, fw.f.Sync()
+	}
+	return n, nil
+}
+
+// Close syncs and closes the file, any Write after it fails with ErrWriterClosed
+func (fw *FileWriter) Close() error {
+	fw.mu.Lock()
+	if fw.closed {
+		fw.mu.Unlock()
+		return nil
+	}
+	fw.closed = true
+	stop, stopped := fw.stop, fw.stopped
+	fw.mu.Unlock()
+
+	// the syncing goroutine needs fw.mu to finish up, so wait without it
+	if stop != nil {
+		close(stop)
+		<-stopped
+	}
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.f == nil {
+		return nil
+	}
+	err := fw.f.Sync()
+	if cerr := fw.f.Close(); err == nil {
+		err = cerr
+	}
+	fw.f = nil
+	return err
+}
+
+// open opens Path for appending, starting the syncing goroutine if it
+// isn't already running, fw.mu must be held
+func (fw *FileWriter) open() error {
+	if err := ensureBaseDir(fw.Path); err != nil {
+		return err
+	}
+	perm := fw.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+	f, err := os.OpenFile(fw.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, perm)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	fw.f, fw.size = f, info.Size()
+
+	if fw.Sync == SyncInterval && fw.stop == nil {
+		every := fw.SyncEvery
+		if every <= 0 {
+			every = DefaultSyncEvery
+		}
+		fw.stop, fw.stopped = make(chan struct{}), make(chan struct{})
+		go fw.syncEvery(every, fw.stop, fw.stopped)
+	}
+	return nil
+}
+
+func (fw *FileWriter) syncEvery(every time.Duration, stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	tick := time.NewTicker(every)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			fw.mu.Lock()
+			if fw.f != nil {
+				fw.f.Sync()
+				if fw.onSync != nil {
+					fw.onSync()
+				}
+			}
+			fw.mu.Unlock()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// rotate moves the current file to Path.1 and opens a new one, fw.mu must be held
+func (fw *FileWriter) rotate() error {
+	if err := fw.f.Close(); err != nil {
+		return err
+	}
+	fw.f = nil
+
+	backups, err := fw.backups()
+	if err != nil {
+		return err
+	}
+	// shift from the oldest down, so nothing gets overwritten,
+	// dropping the ones past MaxBackups on the way
+	for i := len(backups) - 1; i >= 0; i-- {
+		b := backups[i]
+		if fw.MaxBackups > 0 && b.n >= fw.MaxBackups {
+			if err := os.Remove(b.path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.Rename(b.path, fw.backupPath(b.n+1, b.gz)); err != nil {
+			return err
+		}
+	}
+
+	first := fw.backupPath(1, false)
+	if err := os.Rename(fw.Path, first); err != nil {
+		return err
+	}
+	if fw.Compress {
+		if err := gzipFile(first); err != nil {
+			return err
+		}
+	}
+	return fw.open()
+}
+
+type backup struct {
+	n    int
+	gz   bool
+	path string
+}
+
+// backups finds Path.1, Path.2.gz and so on, sorted newest first
+func (fw *FileWriter) backups() ([]backup, error) {
+	dir, base := filepath.Split(fw.Path)
+	if dir == "" {
+		dir = "."
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []backup
+	for _, info := range infos {
+		name := info.Name()
+		if !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		suffix := strings.TrimPrefix(name, base+".")
+		gz := strings.HasSuffix(suffix, ".gz")
+		n, err := strconv.Atoi(strings.TrimSuffix(suffix, ".gz"))
+		if err != nil || n < 1 {
+			continue // not one of ours
+		}
+		backups = append(backups, backup{n: n, gz: gz, path: filepath.Join(dir, name)})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].n < backups[j].n })
+	return backups, nil
+}
+
+func (fw *FileWriter) backupPath(n int, gz bool) string {
+	p := fmt.Sprintf("%s.%d", fw.Path, n)
+	if gz {
+		p += ".gz"
+	}
+	return p
+}
+
+// gzipFile compresses path to path.gz and removes path
+func gzipFile(path string) (err error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(path + ".gz")
+		}
+	}()
+
+	zw := gzip.NewWriter(out)
+	if _, err = io.Copy(zw, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	in.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,163 @@
+package golearn
+
+import (
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFileWriterAppends(t *testing.T) {
+	checkLeaks(t)
+	path := filepath.Join(t.TempDir(), "made", "up", "dirs", "app.log")
+	for _, policy := range []SyncPolicy{SyncNever, SyncEveryWrite, SyncInterval} {
+		synced := make(chan struct{}, 1)
+		fw := &FileWriter{Path: path, Sync: policy, SyncEvery: time.Millisecond}
+		fw.onSync = func() {
+			select {
+			case synced <- struct{}{}:
+			default:
+			}
+		}
+		if _, err := fw.Write([]byte("line\n")); err != nil {
+			t.Fatalf("policy %d: Write() error = %v", policy, err)
+		}
+		if policy == SyncInterval {
+			select {
+			case <-synced:
+			case <-time.After(time.Second):
+				t.Errorf("SyncInterval didn't sync within a second")
+			}
+		}
+		if err := fw.Close(); err != nil {
+			t.Fatalf("policy %d: Close() error = %v", policy, err)
+		}
+		if _, err := fw.Write([]byte("too late")); !errors.Is(err, ErrWriterClosed) {
+			t.Errorf("policy %d: Write() after Close error = %v, want %v", policy, err, ErrWriterClosed)
+		}
+	}
+	// each writer appended rather than starting the file over
+	if got := readFile(t, path); got != "line\nline\nline\n" {
+		t.Errorf("file holds %q, want three lines", got)
+	}
+}
+
+func TestFileWriterConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	fw := &FileWriter{Path: path, MaxSize: 100, MaxBackups: 100}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fw.Write([]byte("0123456789\n"))
+		}()
+	}
+	wg.Wait()
+	if err := fw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// every write landed whole in one file or another
+	files, _ := filepath.Glob(path + "*")
+	var all string
+	for _, f := range files {
+		all += readFile(t, f)
+	}
+	if all != strings.Repeat("0123456789\n", 50) {
+		t.Errorf("files hold %q, want 50 whole lines", all)
+	}
+}
+
+func TestFileWriterRotates(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		fw := &FileWriter{Path: path, MaxSize: 10, MaxBackups: 2, Compress: compress}
+		// 6 bytes each, so every write after the first rotates
+		for _, line := range []string{"aaaaa\n", "bbbbb\n", "ccccc\n", "ddddd\n"} {
+			if _, err := fw.Write([]byte(line)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// a write bigger than MaxSize still goes in, in a file of its own
+		if _, err := fw.Write([]byte("a long line\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := fw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		ext, read := "", readFile
+		if compress {
+			ext, read = ".gz", readGzip
+		}
+		expected := []string{"app.log", "app.log.1" + ext, "app.log.2" + ext}
+		infos, _ := ioutil.ReadDir(dir)
+		var names []string
+		for _, info := range infos {
+			names = append(names, info.Name())
+		}
+		sort.Strings(names)
+		if strings.Join(names, " ") != strings.Join(expected, " ") {
+			t.Fatalf("compress %v: dir holds %v, want %v", compress, names, expected)
+		}
+		if got := readFile(t, path); got != "a long line\n" {
+			t.Errorf("compress %v: app.log holds %q, want %q", compress, got, "a long line\n")
+		}
+		for name, want := range map[string]string{"app.log.1" + ext: "ddddd\n", "app.log.2" + ext: "ccccc\n"} {
+			if got := read(t, filepath.Join(dir, name)); got != want {
+				t.Errorf("compress %v: %s holds %q, want %q", compress, name, got, want)
+			}
+		}
+	}
+}
+
+func TestFileWriterKeepsAllBackups(t *testing.T) {
+	dir := t.TempDir()
+	fw := &FileWriter{Path: filepath.Join(dir, "app.log"), MaxSize: 1}
+	for i := 0; i < 12; i++ {
+		fw.Write([]byte("x"))
+	}
+	fw.Close()
+	// app.log.10 and app.log.11 sort before app.log.2, so check by count
+	if files, _ := filepath.Glob(filepath.Join(dir, "app.log*")); len(files) != 12 {
+		t.Errorf("got %d files, want the log and 11 backups: %v", len(files), files)
+	}
+	if got := readFile(t, filepath.Join(dir, "app.log.11")); got != "x" {
+		t.Errorf("app.log.11 holds %q, want %q", got, "x")
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/aljo242/golearn
 
-go 1.15
+go 1.18
@@ -0,0 +1,144 @@
+package golearn
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden from the current lesson output")
+
+// a normaliser rewrites the parts of a lesson's output that legitimately
+// change from run to run, so everything else can be compared byte for byte
+type normaliser func(string) string
+
+// replace is a normaliser swapping every match of pattern for repl
+func replace(pattern, repl string) normaliser {
+	re := regexp.MustCompile(pattern)
+	return func(s string) string {
+		return re.ReplaceAllString(s, repl)
+	}
+}
+
+// sortSections sorts the lines under each section header (a line ending
+// in "..." or ":") since goroutines print in whatever order they are scheduled
+func sortSections(s string) string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	start := 0
+	for i := 0; i <= len(lines); i++ {
+		if i == len(lines) || strings.HasSuffix(lines[i], "...") || strings.HasSuffix(lines[i], ":") {
+			sort.Strings(lines[start:i])
+			start = i + 1
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// sortSectionsUntil is sortSections for the lines before the line stop,
+// what comes after is printed in order, so it's left alone
+func sortSectionsUntil(stop string) normaliser {
+	return func(s string) string {
+		i := strings.Index(s, "\n"+stop+"\n")
+		if i < 0 {
+			return sortSections(s)
+		}
+		return sortSections(s[:i+1]) + s[i+1:]
+	}
+}
+
+// the lessons run against a DeterministicEnv, so pids, hostnames, timestamps
+// and pointers are already stable and only scheduling needs normalising
+// fmt prints maps with their keys sorted (since go1.12), so the map
+// order in MapsAndStructs is already stable too
+var (
+	lessonNormalisers = map[string][]normaliser{
+		"GoRoutines": {
+			// the closure may see msg before or after it changes,
+			// that race is exactly what the lesson is pointing out
+			replace(`I am a messaged that changed after the go routine call`, "I am a message given to anon func"),
+			replace(`counter: \d+`, "counter: N"),
+			replace(`GOMAXPROCS: \d+\nSetting`, "GOMAXPROCS: N\nSetting"),
+			sortSections,
+		},
+		// the goroutines print in any order, the Logger in the order it's given
+		"Channels": {sortSectionsUntil("Starting Logger....")},
+	}
+)
+
+func normalise(name, out string) string {
+	for _, n := range lessonNormalisers[name] {
+		out = n(out)
+	}
+	return out
+}
+
+// racyLessons print something else under -race, because they skip the
+// data races they show off, so their golden files only hold without it
+var racyLessons = map[string]bool{"GoRoutines": true}
+
+// TestGolden compares what every lesson prints against testdata/<Name>.golden
+// run with -update to regenerate the files after editing a lesson
+func TestGolden(t *testing.T) {
+	for _, l := range Lessons() {
+		l := l
+		t.Run(l.Name(), func(t *testing.T) {
+			var out bytes.Buffer
+			if err := l.Run(context.Background(), &out, deterministicEnv()); err != nil {
+				t.Fatalf("%s: %v", l.Name(), err)
+			}
+			checkGolden(t, l.Name(), out.String())
+		})
+	}
+}
+
+// checkGolden compares a lesson's output with its golden file,
+// or rewrites the file when the suite is run with -update
+func checkGolden(t *testing.T, name, out string) {
+	t.Helper()
+	if raceEnabled && racyLessons[name] {
+		return
+	}
+	got := normalise(name, out)
+	golden := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := ioutil.WriteFile(golden, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s output does not match %s\n%s", name, golden, diffLines(string(want), got))
+	}
+}
+
+// diffLines reports the first line where want and got part ways,
+// which is plenty to track down what changed in a lesson
+func diffLines(want, got string) string {
+	w := strings.Split(want, "\n")
+	g := strings.Split(got, "\n")
+	for i := 0; i < len(w) || i < len(g); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			return fmt.Sprintf("line %d:\n\twant: %q\n\tgot:  %q", i+1, wl, gl)
+		}
+	}
+	return ""
+}
@@ -1,11 +1,11 @@
 package golearn
 
 import (
-	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -14,7 +14,11 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"time"
+
+	"github.com/aljo242/golearn/census"
+	"github.com/aljo242/golearn/containers"
+	"github.com/aljo242/golearn/matrix"
+	"github.com/aljo242/golearn/validate"
 )
 
 // BIG CONCEPT
@@ -32,27 +36,53 @@ var Poop string = "p00p" // exported
 // global var can only be declared like this
 var i int = 69
 
+// every lesson in this file registers itself here, in reading order
+// a new lesson can live in its own file with its own init() calling Register
+func init() {
+	Register(NewLesson("Hello", "the classic hello world", []string{"basics"}, func(_ context.Context, w io.Writer, _ *Env) error {
+		_, err := fmt.Fprintln(w, Hello())
+		return err
+	}))
+	Register(NewLesson("Declarations", "the ways to declare variables", []string{"basics"}, plain(Declarations)))
+	Register(NewLesson("Conversions", "converting between types", []string{"basics", "types"}, plain(Conversions)))
+	Register(NewLesson("Primitives", "the basic types Go provides", []string{"basics", "types"}, plain(Primitives)))
+	Register(NewLesson("Constants", "typed, untyped and enumerated constants", []string{"basics", "types"}, plain(Constants)))
+	Register(NewLesson("ArraysAndSlices", "arrays and the slices that view them", []string{"collections"}, plain(ArraysAndSlices)))
+	Register(NewLesson("MapsAndStructs", "maps, structs, embedding and tags", []string{"collections", "types", "reflection"}, plain(MapsAndStructs)))
+	Register(NewLesson("ControlFlow", "if and switch statements", []string{"control-flow"}, plain(ControlFlow)))
+	Register(NewLesson("Loops", "every loop is a for loop", []string{"control-flow"}, plain(Loops)))
+	Register(NewLesson("DeferPanicRecover", "defer, panic and recover", []string{"control-flow", "errors", "http"}, DeferPanicRecover))
+	Register(NewLesson("Pointers", "pointers without the arithmetic", []string{"memory"}, withEnv(Pointers)))
+	Register(NewLesson("Functions", "parameters, returns, closures and methods", []string{"functions"}, plain(Functions)))
+	Register(NewLesson("Interfaces", "implicit interfaces and composition", []string{"interfaces", "types"}, withEnv(Interfaces)))
+	Register(NewLesson("GoRoutines", "goroutines, wait groups and mutexes", []string{"concurrency"}, withContext(GoRoutines)))
+	Register(NewLesson("Channels", "passing data between goroutines", []string{"concurrency"}, Channels))
+	Register(NewLesson("Filepath", "the path/filepath package", []string{"stdlib", "filesystem"}, withEnv(Filepath)))
+	Register(NewLesson("OS", "the os package", []string{"stdlib", "filesystem", "os"}, withEnv(OS)))
+}
+
 // Hello returns our hello world string
 func Hello() string {
 	return "Hello World!"
 }
 
 // Declarations shows the ways to declare variables in Go
-func Declarations() string {
-	fmt.Println("\nShowing basic declarations in Go...")
+func Declarations(w io.Writer) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing basic declarations in Go...")
 	// var NAME TYPE
 	var a int
 	a = 1
-	fmt.Println("a is", a)
+	fmt.Fprintln(w, "a is", a)
 
 	// var NAME TYPE = VALUE
 	var b int = 22
-	fmt.Println("b is", b)
+	fmt.Fprintln(w, "b is", b)
 
 	// NAME := VALUE
 	// type inference used here
 	c := a + b
-	fmt.Printf("c is %d, %T\n", c, c)
+	fmt.Fprintf(w, "c is %d, %T\n", c, c)
 
 	//we can do group declarations like this
 	var (
@@ -62,37 +92,39 @@ func Declarations() string {
 		i  int = 70 // shadowing rule in Go is that inner-most scope always wins
 	)
 	DD := AA + BB + CC + i
-	fmt.Println("DD is: ", DD)
+	fmt.Fprintln(w, "DD is: ", DD)
 	// if the grouping makes some sense
-	return "Declarations"
+	return nil
 }
 
 // Conversions shows some basic concepts of converting between types
 // in Go's strong typed system
-func Conversions() string {
-	fmt.Println("\nShowing basic conversions in Go's strong type system...")
+func Conversions(w io.Writer) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing basic conversions in Go's strong type system...")
 	// basic float types are 32 and 64 bits
 	j := float32(i)
 	k := float64(i)
 
-	fmt.Printf("i: %d, j: %f, k: %f\n", i, j, k)
+	fmt.Fprintf(w, "i: %d, j: %f, k: %f\n", i, j, k)
 
 	// string conversions
 	var s string
 	//s = string(i)
-	//fmt.Printf("string(%d) = %v\n", i, s)
+	//fmt.Fprintf(w, "string(%d) = %v\n", i, s)
 
 	s = strconv.Itoa(i)
-	fmt.Printf("strconv.Itoa(%d) = %v\n", i, s)
-	return "Conversions"
+	fmt.Fprintf(w, "strconv.Itoa(%d) = %v\n", i, s)
+	return nil
 }
 
 // Primitives details the basic types Go provides
-func Primitives() string {
-	fmt.Println("\nShowing the basic types in Go...")
+func Primitives(w io.Writer) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing the basic types in Go...")
 	// boolean
 	var n bool = 2 == 2
-	fmt.Printf("var = %v, %T\n", n, n)
+	fmt.Fprintf(w, "var = %v, %T\n", n, n)
 
 	// numerics
 	var (
@@ -115,44 +147,44 @@ func Primitives() string {
 		comp64  complex64  = 1 + 3i
 		comp128 complex128 = 1 + 4i
 	)
-	fmt.Printf("var = %v, %T\n", signedInt, signedInt)
-	fmt.Printf("var = %v, %T\n", unsignedInt, unsignedInt)
-	fmt.Printf("var = %v, %T\n", signedInt8, signedInt8)
-	fmt.Printf("var = %v, %T\n", unsignedInt8, unsignedInt8)
-	fmt.Printf("byte alias = %v, %T\n", byteAlias, byteAlias)
-	fmt.Printf("var = %v, %T\n", signedInt16, signedInt16)
-	fmt.Printf("var = %v, %T\n", unsignedInt16, unsignedInt16)
-	fmt.Printf("var = %v, %T\n", signedInt32, signedInt32)
-	fmt.Printf("var = %v, %T\n", unsignedInt32, unsignedInt32)
-	fmt.Printf("var = %v, %T\n", signedInt64, signedInt64)
-	fmt.Printf("var = %v, %T\n", unsignedInt64, unsignedInt64)
-	fmt.Printf("var = %v, %T\n", f32, f32)
-	fmt.Printf("var = %v, %T\n", f64, f64)
-	fmt.Printf("var = %v, %T\n", comp64, comp64)
-	fmt.Printf("var = %v, %T\n", comp128, comp128)
-	fmt.Printf("real(comp128) = %v, %T\n", real(comp128), real(comp128))
-	fmt.Printf("imag(comp128) = %v, %T\n", imag(comp128), imag(comp128))
+	fmt.Fprintf(w, "var = %v, %T\n", signedInt, signedInt)
+	fmt.Fprintf(w, "var = %v, %T\n", unsignedInt, unsignedInt)
+	fmt.Fprintf(w, "var = %v, %T\n", signedInt8, signedInt8)
+	fmt.Fprintf(w, "var = %v, %T\n", unsignedInt8, unsignedInt8)
+	fmt.Fprintf(w, "byte alias = %v, %T\n", byteAlias, byteAlias)
+	fmt.Fprintf(w, "var = %v, %T\n", signedInt16, signedInt16)
+	fmt.Fprintf(w, "var = %v, %T\n", unsignedInt16, unsignedInt16)
+	fmt.Fprintf(w, "var = %v, %T\n", signedInt32, signedInt32)
+	fmt.Fprintf(w, "var = %v, %T\n", unsignedInt32, unsignedInt32)
+	fmt.Fprintf(w, "var = %v, %T\n", signedInt64, signedInt64)
+	fmt.Fprintf(w, "var = %v, %T\n", unsignedInt64, unsignedInt64)
+	fmt.Fprintf(w, "var = %v, %T\n", f32, f32)
+	fmt.Fprintf(w, "var = %v, %T\n", f64, f64)
+	fmt.Fprintf(w, "var = %v, %T\n", comp64, comp64)
+	fmt.Fprintf(w, "var = %v, %T\n", comp128, comp128)
+	fmt.Fprintf(w, "real(comp128) = %v, %T\n", real(comp128), real(comp128))
+	fmt.Fprintf(w, "imag(comp128) = %v, %T\n", imag(comp128), imag(comp128))
 
 	// numeric operations
-	fmt.Println("Basic Numeric Type Operations:")
+	fmt.Fprintln(w, "Basic Numeric Type Operations:")
 	a := 10
 	b := 3
-	fmt.Printf("(%d + %d) = %d\n", a, b, a+b)
-	fmt.Printf("(%d - %d) = %d\n", a, b, a-b)
-	fmt.Printf("(%d * %d) = %d\n", a, b, a*b)
-	fmt.Printf("(%d / %d) = %d\n", a, b, a/b)
-	fmt.Printf("(%d %% %d) = %d\n", a, b, a%b)
-
-	fmt.Println("Basic Bit Operations:")
-	fmt.Printf("(%d & %d) = %d\n", a, b, a&b)   // AND
-	fmt.Printf("(%d | %d) = %d\n", a, b, a|b)   // OR
-	fmt.Printf("(%d ^ %d) = %d\n", a, b, a^b)   // XOR
-	fmt.Printf("(%d &^ %d) = %d\n", a, b, a&^b) // NAND
-	fmt.Printf("(%d << %d) = %d\n", a, b, a<<b) // shift left
-	fmt.Printf("(%d >> %d) = %d\n", a, b, a>>b) // shift right
+	fmt.Fprintf(w, "(%d + %d) = %d\n", a, b, a+b)
+	fmt.Fprintf(w, "(%d - %d) = %d\n", a, b, a-b)
+	fmt.Fprintf(w, "(%d * %d) = %d\n", a, b, a*b)
+	fmt.Fprintf(w, "(%d / %d) = %d\n", a, b, a/b)
+	fmt.Fprintf(w, "(%d %% %d) = %d\n", a, b, a%b)
+
+	fmt.Fprintln(w, "Basic Bit Operations:")
+	fmt.Fprintf(w, "(%d & %d) = %d\n", a, b, a&b)   // AND
+	fmt.Fprintf(w, "(%d | %d) = %d\n", a, b, a|b)   // OR
+	fmt.Fprintf(w, "(%d ^ %d) = %d\n", a, b, a^b)   // XOR
+	fmt.Fprintf(w, "(%d &^ %d) = %d\n", a, b, a&^b) // NAND
+	fmt.Fprintf(w, "(%d << %d) = %d\n", a, b, a<<b) // shift left
+	fmt.Fprintf(w, "(%d >> %d) = %d\n", a, b, a>>b) // shift right
 
 	// Text Types
-	fmt.Println("Basic Text Types:")
+	fmt.Fprintln(w, "Basic Text Types:")
 	var (
 		// string literals are "string" double quotes
 		// rune literals are 'rune' single quotes
@@ -162,13 +194,13 @@ func Primitives() string {
 		byteSlice        = []byte(s1) // slice of byte
 		r         rune   = 'a'        // runes are int32
 	)
-	fmt.Printf("var = %v, %T\n", s1, s1)
-	fmt.Printf("var = %v, %T\n", byteSlice, byteSlice)
-	fmt.Printf("rune = %v, %T\n", r, r)
+	fmt.Fprintf(w, "var = %v, %T\n", s1, s1)
+	fmt.Fprintf(w, "var = %v, %T\n", byteSlice, byteSlice)
+	fmt.Fprintf(w, "rune = %v, %T\n", r, r)
 
 	// Text Operations
-	fmt.Printf("(s1 + and + s2) = %s\n", s1+and+s2) // string concatenation
-	return "Primitives"
+	fmt.Fprintf(w, "(s1 + and + s2) = %s\n", s1+and+s2) // string concatenation
+	return nil
 }
 
 // Constants covers:
@@ -177,8 +209,9 @@ func Primitives() string {
 // untyped constants
 // enumerated constants
 // enumeration expressions
-func Constants() string {
-	fmt.Println("\nShowing off constants in Go...")
+func Constants(w io.Writer) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing off constants in Go...")
 	// constants preceded by "const" keyword
 	// do not name constants as MYCONST in Go
 	// because the capital first letter will
@@ -210,18 +243,21 @@ func Constants() string {
 		e
 	)
 
+	// give a named type's iota constants names at runtime
+	// with golearn gen enum (see package enumgen)
+
 	// a call to iota is limited within the scope of a
 	// single enumerated expression
 	const (
 		f = iota
 		g
 	)
-	fmt.Printf("const c = iota -> %v, %T\n", c, c)
-	fmt.Printf("const d = iota -> %v, %T\n", d, d)
-	fmt.Printf("const e = iota -> %v, %T\n", e, e)
+	fmt.Fprintf(w, "const c = iota -> %v, %T\n", c, c)
+	fmt.Fprintf(w, "const d = iota -> %v, %T\n", d, d)
+	fmt.Fprintf(w, "const e = iota -> %v, %T\n", e, e)
 
-	fmt.Printf("const f = iota -> %v, %T\n", f, f)
-	fmt.Printf("const g = iota -> %v, %T\n", g, g)
+	fmt.Fprintf(w, "const f = iota -> %v, %T\n", f, f)
+	fmt.Fprintf(w, "const g = iota -> %v, %T\n", g, g)
 
 	// we can pack multiple bit flags into a single byte
 	const (
@@ -236,11 +272,22 @@ func Constants() string {
 		canSeeSouthAmericca
 	)
 
-	fmt.Println("Bit flag packed byte:")
+	fmt.Fprintln(w, "Bit flag packed byte:")
 	var roles byte = isAdmin | canSeeFinancials | canSeeEurope
-	fmt.Printf("\t%b\n", roles)
-	fmt.Printf("Is Admin? %v\n", isAdmin&roles == isAdmin)            //000001 & 100101 = 000001
-	fmt.Printf("Is HQ? %v\n", isHeadquarters&roles == isHeadquarters) // 000010 & 100101 = 000000
+	fmt.Fprintf(w, "\t%b\n", roles)
+	fmt.Fprintf(w, "Is Admin? %v\n", isAdmin&roles == isAdmin)            //000001 & 100101 = 000001
+	fmt.Fprintf(w, "Is HQ? %v\n", isHeadquarters&roles == isHeadquarters) // 000010 & 100101 = 000000
+
+	// Role (see role.go) is the same idea as a real type,
+	// backed by a uint64 so it isn't stuck at 8 flags,
+	// that can print its flags by name and parse them back
+	role := RoleAdmin | RoleFinancials | RoleEurope
+	fmt.Fprintf(w, "Role: %v (%b)\n", role, uint64(role))
+	role.Set(RoleAntarctica)
+	role.Clear(RoleAdmin)
+	fmt.Fprintf(w, "Role after Set and Clear: %v, Is Admin? %v\n", role, role.Has(RoleAdmin))
+	parsed, err := ParseRole("asia|headquarters")
+	fmt.Fprintf(w, "Parsed role: %v (%b), err %v\n", parsed, uint64(parsed), err)
 
 	// IN SUMMARY
 	// constants are immutable, but CAN be shadowed
@@ -264,14 +311,15 @@ func Constants() string {
 	// operations that can be determined at compile time are allowed
 	//	use the iota construct paired with operations to create related constants
 	//	arithmetic, bitwise operations, bitshifting
-	return "Constants"
+	return nil
 }
 
 // ArraysAndSlices first details arrays, which are the basis
 // for slices, then slices, which allow for dynamic views
 // of allocated memory
-func ArraysAndSlices() string {
-	fmt.Println("\nShowing Array and Slices Basics in Go...")
+func ArraysAndSlices(w io.Writer) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing Array and Slices Basics in Go...")
 	// Arrays are declated using:
 	// NAME := [SIZE]TYPE{initializer_list}
 	// where size is a compile time constant
@@ -284,24 +332,24 @@ func ArraysAndSlices() string {
 
 	// NAME := [SIZE]TYPE{initializer_list}
 	grades := [3]int{93, 45, 59}
-	fmt.Println(grades) // can print arrays like this
+	fmt.Fprintln(w, grades) // can print arrays like this
 	for index, grade := range grades {
-		fmt.Println(index, grade) // or iterate using range construct
+		fmt.Fprintln(w, index, grade) // or iterate using range construct
 	}
 
 	// NAME := [...]TYPE{initializer_list}
 	grades2 := [...]int{93, 45, 59}
-	fmt.Println(grades2) // can print arrays like this
+	fmt.Fprintln(w, grades2) // can print arrays like this
 	for index, grade := range grades2 {
-		fmt.Println(index, grade) // or iterate using range construct
+		fmt.Fprintln(w, index, grade) // or iterate using range construct
 	}
 
 	// var NAME [SIZE]TYPE
 	var grades3 [3]int
-	grades3 = grades2    // array assigmment here uses a copy
-	fmt.Println(grades3) // can print arrays like this
+	grades3 = grades2        // array assigmment here uses a copy
+	fmt.Fprintln(w, grades3) // can print arrays like this
 	for index, grade := range grades3 {
-		fmt.Println(index, grade) // or iterate using range construct
+		fmt.Fprintln(w, index, grade) // or iterate using range construct
 	}
 
 	// ARRAY ASSIGNMENT IN GO IS ACTUALLY ALWAYS A COPY!!!!
@@ -311,18 +359,18 @@ func ArraysAndSlices() string {
 	// grades3 is assigned from grades2, but we know this is a cop
 	// so, if we modify grades3[2], only grades3 is modified, not grades2
 	grades2[2] = 12
-	fmt.Println("Arrays are copied in go, so modifying a copied array will not modify the orginal array:")
-	fmt.Println(grades2, grades3)
+	fmt.Fprintln(w, "Arrays are copied in go, so modifying a copied array will not modify the orginal array:")
+	fmt.Fprintln(w, grades2, grades3)
 
 	// if we use this syntax, we are taking a reference to the data
 	// so modifications made on grades3p will affect the underlying grades
 	grades3p := &grades2
 	grades3p[2] = 12
-	fmt.Println("Arrays can be taken by reference with &, and then will  modify the orginal array:")
-	fmt.Println(grades2, grades3p)
+	fmt.Fprintln(w, "Arrays can be taken by reference with &, and then will  modify the orginal array:")
+	fmt.Fprintln(w, grades2, grades3p)
 
-	if len(grades) == len(grades2) && len(grades) == len(grades3) {
-		fmt.Println("Length of arrays are all the same!")
+	if len(grades) == len(grades2) && len(grades2) == len(grades3) {
+		fmt.Fprintln(w, "Length of arrays are all the same!")
 	}
 
 	// multi dim arrays
@@ -330,7 +378,11 @@ func ArraysAndSlices() string {
 	identityMatrix[0] = [3]int{1, 0, 0}
 	identityMatrix[1] = [3]int{0, 1, 0}
 	identityMatrix[2] = [3]int{0, 0, 1}
-	fmt.Println(identityMatrix)
+	fmt.Fprintln(w, identityMatrix)
+
+	// package matrix does this for any size, keeping the elements in one flat
+	// slice, so a Matrix is shared rather than copied, like slices below
+	fmt.Fprintln(w, matrix.Identity(3))
 
 	// SLICES
 	// slices are projections onto an underlying array
@@ -347,21 +399,21 @@ func ArraysAndSlices() string {
 	// since slices are "views" this assignment does not copy
 	// both slices view the same underlying array
 	slice2 := slice
-	fmt.Println(slice, "Length:", len(slice), "Capacity:", cap(slice))
+	fmt.Fprintln(w, slice, "Length:", len(slice), "Capacity:", cap(slice))
 
-	fmt.Println("Modifying copied slice of original slice...")
+	fmt.Fprintln(w, "Modifying copied slice of original slice...")
 	slice2[2] = 4
-	fmt.Println(slice, "Length:", len(slice), "Capacity:", cap(slice))
+	fmt.Fprintln(w, slice, "Length:", len(slice), "Capacity:", cap(slice))
 
 	// other slice declarations
 	a := slice[:]   // slice of all elements
 	b := slice[3:]  // slice of index 3 and up			(element 3 to 9)
 	c := slice[:6]  // slice up to index 6				(element 1 to 6)
 	d := slice[3:6] // slice from index 3 up to index 6	(element 4 to 6)
-	fmt.Println(a)
-	fmt.Println(b)
-	fmt.Println(c)
-	fmt.Println(d)
+	fmt.Fprintln(w, a)
+	fmt.Fprintln(w, b)
+	fmt.Fprintln(w, c)
+	fmt.Fprintln(w, d)
 
 	// slices can also come from arrays
 	// this makes plenty of sense, as they are by definition
@@ -369,20 +421,20 @@ func ArraysAndSlices() string {
 	// note that making a slice from an array will mean the length and capacity
 	// should be the same, since the length of an ARRAY is equivalent to its capacity
 	f := [3]int{3, 2, 1}
-	fmt.Println("Making a slice from an array...")
+	fmt.Fprintln(w, "Making a slice from an array...")
 	slicef := f[:]
-	fmt.Println("Slice:", slicef, "len:", len(slicef), "cap:", cap(slicef))
+	fmt.Fprintln(w, "Slice:", slicef, "len:", len(slicef), "cap:", cap(slicef))
 
 	// one more way to make a slice
 	// is to use the builtin "make" functionality
 	// SLICE := make([]TYPE, LENGTH, CAPACITY)
 	slice = make([]int, 3, 100)
-	fmt.Println("Making a slice using make([]int, 3)")
-	fmt.Println("Slice:", slice, "len:", len(slice), "cap:", cap(slice))
+	fmt.Fprintln(w, "Making a slice using make([]int, 3)")
+	fmt.Fprintln(w, "Slice:", slice, "len:", len(slice), "cap:", cap(slice))
 	slice = append(slice, 4)
-	fmt.Println("Slice:", slice, "len:", len(slice), "cap:", cap(slice))
+	fmt.Fprintln(w, "Slice:", slice, "len:", len(slice), "cap:", cap(slice))
 	slice = append(slice, 5, 6, 7, 8, 89, 190, 4)
-	fmt.Println("Slice:", slice, "len:", len(slice), "cap:", cap(slice))
+	fmt.Fprintln(w, "Slice:", slice, "len:", len(slice), "cap:", cap(slice))
 
 	// concatenating slices
 	// the syntax:
@@ -390,7 +442,7 @@ func ArraysAndSlices() string {
 	// 		decomposes SLICE_C into a literals list which can be accepted
 	//		by the append() function
 	slice = append(slice, slicef...)
-	fmt.Println("Slice:", slice, "len:", len(slice), "cap:", cap(slice))
+	fmt.Fprintln(w, "Slice:", slice, "len:", len(slice), "cap:", cap(slice))
 
 	// using slices like a stack
 	// append() is basically push()
@@ -407,7 +459,22 @@ func ArraysAndSlices() string {
 
 	// what about removing an element from the middle?
 	stack = append(stack[:2], stack[3:]...)
-	fmt.Println("Slice:", stack, "len:", len(stack), "cap:", cap(stack))
+	fmt.Fprintln(w, "Slice:", stack, "len:", len(stack), "cap:", cap(stack))
+
+	// note stack[1:] never gives the popped front back, the slice just
+	// starts further along the same array, which stays alive as long as
+	// the slice does
+	// package containers wraps these idioms up as Stack, Queue, Deque and
+	// Ring (with generics, a type parameter for what they hold), and they
+	// clear what they pop and shrink as they empty
+	var dq containers.Deque[int]
+	for _, v := range stack {
+		dq.PushBack(v) // push
+	}
+	dq.PushFront(0)           // push_front, which a slice can only do by copying
+	front, _ := dq.PopFront() // pop_front
+	back, _ := dq.PopBack()   // pop_back
+	fmt.Fprintln(w, "Deque:", dq.Values(), "popped", front, "and", back)
 
 	// SUMMARY
 	// Arrays are contigiuous collections of items of the same type
@@ -422,7 +489,7 @@ func ArraysAndSlices() string {
 	//		if you keep appending items
 	//		slice copies all will refer to the same underlying array
 	// 		since they are just VIEWs of a real place in memory
-	return "ArraysAndSlices"
+	return nil
 }
 
 // Doctor is a basic type containing a Doctor Who doctor number, actor name, and slice of companion names
@@ -441,7 +508,7 @@ type Doctor struct {
 
 // Animal is a basic base struct for animal
 type Animal struct {
-	Name   string `reqired max:"100"`
+	Name   string `required:"true" max:"100"`
 	Origin string
 }
 
@@ -456,13 +523,14 @@ type Bird struct {
 	// Name string
 	// Origin string
 	// that it got from being composed of Animal
-	SpeedKPH float32
+	SpeedKPH float32 `min:"0" max:"400"`
 	CanFly   bool
 }
 
 // MapsAndStructs details other basic container primitives in Go
-func MapsAndStructs() string {
-	fmt.Println("\nShowing Maps and Structs Basics in Go...")
+func MapsAndStructs(w io.Writer) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing Maps and Structs Basics in Go...")
 
 	// Maps behave the way they do in any language
 	// syntax
@@ -471,35 +539,57 @@ func MapsAndStructs() string {
 	//		because an equivalency check is performed to see if a key
 	//		is in the map
 
-	statePopulations := map[string]int{
-		"California":   39250017,
-		"Texas":        27862596,
-		"Florida":      20612439,
-		"New York":     19745289,
-		"Pennsylvania": 12802503,
-		"Illinois":     12801539,
-		"Ohio":         11614373,
-	}
-	statePopulations["Georgia"] = 10310371 // add a new element
+	// a map literal looks like
+	//	statePopulations := map[string]int{
+	//		"California": 39250017,
+	//		"Texas":      27862596,
+	//		...
+	//	}
+	// but the populations live in package census, so this builds
+	// the same map out of its seven biggest states
+	statePopulations := census.Populations(census.Top(7))
+	georgia, _ := census.ByName("Georgia")
+	statePopulations[georgia.Name] = georgia.Population // add a new element
 
-	fmt.Println(statePopulations)
+	fmt.Fprintln(w, statePopulations)
 
 	// can also declare maps using the "make" syntax
 	otherMap := make(map[string]int)
 
 	// we can read and write to maps by using their key like an array index
 	// this one below CREATES a new key
-	otherMap["key"] = 1          // write
-	fmt.Println(otherMap["key"]) // read
+	otherMap["key"] = 1              // write
+	fmt.Fprintln(w, otherMap["key"]) // read
 
 	// note that maps do not have some kind of ordering
 	// if you modify a map and then print it,
 	//		the ordering might just be some random shit
+	// (fmt sorts the keys before printing a map, but a range over
+	// one really does come out in a different order every run)
+	// containers.SortedKeys gives a range that's the same every time,
+	// and a containers.OrderedMap remembers the order keys were added in
+	ordered := containers.NewOrderedMap[string, int]()
+	for _, state := range []string{"Texas", "Ohio", "California", "Georgia"} {
+		ordered.Set(state, statePopulations[state])
+	}
+	fmt.Fprint(w, "Sorted keys:")
+	for _, state := range containers.SortedKeys(statePopulations) {
+		fmt.Fprintf(w, " %s", state)
+	}
+	fmt.Fprint(w, "\nInsertion order:")
+	for e := ordered.Front(); e != nil; e = e.Next() {
+		fmt.Fprintf(w, " %s", e.Key)
+	}
+	data, err := json.Marshal(ordered)
+	if err != nil {
+		return lessonError("MapsAndStructs", ErrOutput, "marshalling an OrderedMap", err)
+	}
+	fmt.Fprintf(w, "\nAs JSON: %s\n", data)
 
 	// we can delete items from maps too
-	fmt.Println(statePopulations)
+	fmt.Fprintln(w, statePopulations)
 	delete(statePopulations, "Georgia") // deletes an item from a map
-	fmt.Println(statePopulations)
+	fmt.Fprintln(w, statePopulations)
 
 	// if you query a key that does not exist in a map
 	// the return will be the zero-init value for the value type
@@ -507,17 +597,17 @@ func MapsAndStructs() string {
 	// which you can use to check if the value was actually in the map
 	pop, ok := statePopulations["Georgia"]
 	if !ok {
-		fmt.Println("Key not in map, returned value is:", pop)
+		fmt.Fprintln(w, "Key not in map, returned value is:", pop)
 	}
 
 	// len(map) returns the number of elements in a map
-	fmt.Println("Map length:", len(statePopulations))
+	fmt.Fprintln(w, "Map length:", len(statePopulations))
 
 	// maps are reference types, so modifications made to copies
 	// will modify the original
 	sp := statePopulations
 	delete(sp, "Ohio")
-	fmt.Println("Map length after delete on a copy:", len(statePopulations))
+	fmt.Fprintln(w, "Map length after delete on a copy:", len(statePopulations))
 
 	// example of initalizing a struct with named fields
 	// NAME = STRUCT {
@@ -535,11 +625,11 @@ func MapsAndStructs() string {
 			"Sarah Jane Smith",
 		},
 	}
-	fmt.Println(aDoctor)
+	fmt.Fprintln(w, aDoctor)
 
 	// can access struct members using the . syntax
 	if aDoctor.Number == 3 {
-		fmt.Println("Doctor is number", aDoctor.Number)
+		fmt.Fprintln(w, "Doctor is number", aDoctor.Number)
 	}
 
 	// anonymous structs can be created locally and do not have type names
@@ -551,9 +641,9 @@ func MapsAndStructs() string {
 	// can use references to copy by refence
 	pAnon := &anon
 	pAnon.name = "Jimmy"
-	fmt.Println("OG:", anon)
-	fmt.Println("Value Copy Modified", anotherAnon)
-	fmt.Println("Reference Modified:", pAnon)
+	fmt.Fprintln(w, "OG:", anon)
+	fmt.Fprintln(w, "Value Copy Modified", anotherAnon)
+	fmt.Fprintln(w, "Reference Modified:", pAnon)
 
 	// creating a struct with the Composed struct, Bird
 	// which is has the Animal struct Embedded in it
@@ -564,7 +654,7 @@ func MapsAndStructs() string {
 	b.Origin = "Australia"
 	b.SpeedKPH = 48
 	b.CanFly = false
-	fmt.Println(b)
+	fmt.Fprintln(w, b)
 
 	// because when using the named initializer syntax, its like this:
 	// therefor you need to kind of know more about the layout of the struct
@@ -573,12 +663,24 @@ func MapsAndStructs() string {
 		SpeedKPH: 48,
 		CanFly:   false,
 	}
-	fmt.Println(c)
+	fmt.Fprintln(w, c)
 
 	// using reflection in Go
+	// (Explore in explore.go walks every field like this, try golearn inspect Bird)
 	t := reflect.TypeOf(Animal{})
 	field, _ := t.FieldByName("Name")
-	fmt.Println(field.Tag)
+	fmt.Fprintln(w, field.Tag)
+
+	// the validate package reads those tags to check values,
+	// all the way down into the embedded Animal
+	fmt.Fprintln(w, "Valid bird:", validate.Struct(c))
+	fmt.Fprintln(w, "Invalid bird:", validate.Struct(Bird{SpeedKPH: -1}))
+
+	// and a tag it doesn't know is an error too, not just ignored
+	typo := struct {
+		Name string `reqired:"true"`
+	}{}
+	fmt.Fprintln(w, "Misspelled tag:", validate.Struct(typo))
 
 	// SUMMARY
 	// Maps are collections of value types accessed by keys
@@ -600,29 +702,23 @@ func MapsAndStructs() string {
 
 	// Can use the "reflect" library
 	// to query type, field, and tag info
-	// can use these for validation framework
-	return "MapsAndStructs"
+	// can use these for validation framework, see package validate
+	return nil
 }
 
-func returnTrue() bool {
-	fmt.Println("TRUE")
+func returnTrue(w io.Writer) bool {
+	fmt.Fprintln(w, "TRUE")
 	return true
 }
 
 // ControlFlow details common control flow in Go (if, switch)
-func ControlFlow() string {
-	fmt.Println("\nShowing Control Flow Basics in Go...")
+func ControlFlow(w io.Writer) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing Control Flow Basics in Go...")
 
 	// a lot of IDIOMATIC GO uses initializers within if statements
-	statePopulations := map[string]int{
-		"California":   39250017,
-		"Texas":        27862596,
-		"Florida":      20612439,
-		"New York":     19745289,
-		"Pennsylvania": 12802503,
-		"Illinois":     12801539,
-		"Ohio":         11614373,
-	}
+	// (the same statePopulations as MapsAndStructs, from package census)
+	statePopulations := census.Populations(census.Top(7))
 
 	// here we do
 	// if initialize; boolean {}
@@ -630,7 +726,10 @@ func ControlFlow() string {
 	// this is nice, because below pop and ok
 	// are only local to the if statement, just like (for i = 0; ...)
 	if pop, ok := statePopulations["Florida"]; ok {
-		fmt.Println(pop)
+		fmt.Fprintln(w, pop)
+	}
+	if s, ok := census.ByAbbr("GA"); ok {
+		fmt.Fprintf(w, "%s is number %d in the %s\n", s.Name, s.Rank, s.Region)
 	}
 
 	// remember that if there are multiple conditionals
@@ -640,14 +739,14 @@ func ControlFlow() string {
 	num := num1
 
 	// both of these will return true
-	// but returnTrue() will only execute for the 2nd if stmt
-	if num < 5 || returnTrue() || num > 105 {
-		fmt.Println("Multi-statement is true")
+	// but returnTrue(w) will only execute for the 2nd if stmt
+	if num < 5 || returnTrue(w) || num > 105 {
+		fmt.Fprintln(w, "Multi-statement is true")
 	}
 
 	num = num2
-	if num < 5 || returnTrue() || num > 105 {
-		fmt.Println("Multi-statement is true")
+	if num < 5 || returnTrue(w) || num > 105 {
+		fmt.Fprintln(w, "Multi-statement is true")
 	}
 
 	// IDIOMATIC Go uses switch statements instead of
@@ -659,11 +758,11 @@ func ControlFlow() string {
 	// overlapping cases are NOT allowed
 	switch i := 2 + 3; i { // can use initializers just like if
 	case 1:
-		fmt.Println("one")
+		fmt.Fprintln(w, "one")
 	case 2:
-		fmt.Println("two")
+		fmt.Fprintln(w, "two")
 	case 3, 4, 5: // can have multiple tests as a comma-separated list
-		fmt.Println("three, four, five")
+		fmt.Fprintln(w, "three, four, five")
 		fallthrough
 		// since Go has "break" implied in switch statements,
 		// to get fallthrough behavior (which is the default in C-likes)
@@ -672,14 +771,14 @@ func ControlFlow() string {
 		// but at case 20:, the break is still there
 		// so basically its the converse of C-like style
 	case 20:
-		fmt.Println("also maybe twenty (from fallthrough)")
+		fmt.Fprintln(w, "also maybe twenty (from fallthrough)")
 		if i == 5 {
 			break //  we can still insert breaks so we can skip stuff
 			// perhaps an error occurs and we want to break to resolve
 		}
-		fmt.Println("PLEASE DONT PRINT THIS")
+		fmt.Fprintln(w, "PLEASE DONT PRINT THIS")
 	default:
-		fmt.Println("default")
+		fmt.Fprintln(w, "default")
 	}
 
 	// another unique switch syntax does not use a tag
@@ -688,14 +787,16 @@ func ControlFlow() string {
 	// and use it in conditionals
 	// this is where switch-case becomes the defacto if -> else if -> else
 	// in Go
-	i = i * 3
+	// (shadow the package level i, writing to it would change it
+	// for every other lesson, and every later run of this one)
+	i := i * 3
 	switch {
 	case i <= 10:
-		fmt.Println("LEQ 20")
+		fmt.Fprintln(w, "LEQ 20")
 	case i <= 20:
-		fmt.Println("LEQ 20")
+		fmt.Fprintln(w, "LEQ 20")
 	default:
-		fmt.Println("Greater than 20")
+		fmt.Fprintln(w, "Greater than 20")
 	}
 
 	// TYPE SWITCHING
@@ -708,23 +809,24 @@ func ControlFlow() string {
 	var j interface{} = 1
 	switch j.(type) {
 	case int:
-		fmt.Println("j is an int")
+		fmt.Fprintln(w, "j is an int")
 	case float32, float64:
-		fmt.Println("j is a float")
+		fmt.Fprintln(w, "j is a float")
 	case string:
-		fmt.Println("j is a string")
+		fmt.Fprintln(w, "j is a string")
 	default:
-		fmt.Println("j is another type")
+		fmt.Fprintln(w, "j is another type")
 	}
-	return "ControlFlow"
+	return nil
 }
 
 // Loops details common loop structures in Go (ONLY for)
-func Loops() string {
-	fmt.Println("\nShowing Loop Basics in Go...")
+func Loops(w io.Writer) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing Loop Basics in Go...")
 
 	// All looping statements in Go use for
-	fmt.Println("All looping statements in Go use for")
+	fmt.Fprintln(w, "All looping statements in Go use for")
 
 	// simple loops
 	// classic syntax
@@ -734,7 +836,7 @@ func Loops() string {
 
 	// note Go does not have "++i", only "i++"
 	for i := 0; i < 5; i++ {
-		fmt.Println(i)
+		fmt.Fprintln(w, i)
 	}
 
 	// for loop with multiple values initialized
@@ -742,27 +844,27 @@ func Loops() string {
 	// VAR1, VAR2 := VALUE1, VALUE2  	initialization
 	// VAR1, VAR2 = VALUE1, VALUE2		assigmnent
 	for i, j := 0, 5; i < 5; i, j = i+1, j-1 {
-		fmt.Println(i, j)
+		fmt.Fprintln(w, i, j)
 	}
 
 	// using an alread declared variable
 	// here, i is scoped to the main function
 	i := 0
 	for ; i < 5; i++ {
-		fmt.Println(i)
+		fmt.Fprintln(w, i)
 	}
 
 	// removing the iteration statement
 	// we now just have the Go equivalent of a
 	// while loop
 	for i == 5 {
-		fmt.Println(i)
+		fmt.Fprintln(w, i)
 		i = 6
 	}
 
 	// infinite while loop
 	for {
-		fmt.Println("inside infinite loop, break me out!")
+		fmt.Fprintln(w, "inside infinite loop, break me out!")
 		break // break just exits the entire loop
 	}
 
@@ -772,7 +874,7 @@ func Loops() string {
 		if i%2 == 0 { // check if even
 			continue
 		}
-		fmt.Println(i)
+		fmt.Fprintln(w, i)
 	}
 
 	// can use labels to label the loop we want to break from in Go
@@ -781,12 +883,12 @@ OuterLoop:
 	InnerLoop:
 		for j := 1; j < 3; j++ {
 			ij := i * j
-			fmt.Println(ij)
+			fmt.Fprintln(w, ij)
 			if ij >= 3 {
-				fmt.Println("Breaking from Outer Loop")
+				fmt.Fprintln(w, "Breaking from Outer Loop")
 				break OuterLoop
 			} else if ij == 45 {
-				fmt.Println("Breaking from Inner Loop")
+				fmt.Fprintln(w, "Breaking from Inner Loop")
 				break InnerLoop
 			}
 		}
@@ -796,15 +898,15 @@ OuterLoop:
 	// for test {}
 	// for
 
-	return "Loops"
+	return nil
 }
 
-func deferredGuy1() {
-	fmt.Println("1. I was deferred at the beginning of DeferPanicRecover()")
+func deferredGuy1(w io.Writer) {
+	fmt.Fprintln(w, "1. I was deferred at the beginning of DeferPanicRecover()")
 }
 
-func deferredGuy2() {
-	fmt.Println("2. I was deferred at the beginning of DeferPanicRecover()")
+func deferredGuy2(w io.Writer) {
+	fmt.Fprintln(w, "2. I was deferred at the beginning of DeferPanicRecover()")
 }
 
 // this is an interesting trick where we are passing an invocation
@@ -813,28 +915,29 @@ func deferredGuy2() {
 // so, thinking about it more, the semantics are pretty similar
 // to try-catch with exceptions
 
-func panicker() {
-	fmt.Println("About to panic")
+func panicker(w io.Writer, env *Env) {
+	fmt.Fprintln(w, "About to panic")
 	defer func() {
 		if err := recover(); err != nil {
-			log.Println("Error in Recover:", err)
+			env.logf(w, "Error in Recover: %v", err)
 		}
 	}() // () here is the actual invocation of the func
 	panic("Please Recover Me")
-	fmt.Println("End of panicker")
+	// fmt.Fprintln(w, "End of panicker") would never run, the panic unwinds first
 }
 
 // DeferPanicRecover shows some advanced control flow constructs in Go
-func DeferPanicRecover() string {
-	fmt.Println("\nShowing Defer, Panic, Recover Basics in Go...")
+func DeferPanicRecover(ctx context.Context, w io.Writer, env *Env) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing Defer, Panic, Recover Basics in Go...")
 
 	// DEFER
 	// deferred functions execute when the context it is called inside
 	// returns to the context which called it
 	// meaning, when this function returns, the deferred
 	// functions are then executed
-	defer deferredGuy1()
-	defer deferredGuy2()
+	defer deferredGuy1(w)
+	defer deferredGuy2(w)
 	// LIFO ordering
 	// so think of the deferred functions as being pushed onto a stack
 	// think also of closing resources in the opposite order you opened them
@@ -849,18 +952,30 @@ func DeferPanicRecover() string {
 	//		}
 	//		defer res.Close()
 
-	res, err := http.Get("http://www.google.com/robots.txt")
+	// env.fetcher() is http.DefaultClient unless we were handed another,
+	// so this is http.Get(env.robotsURL()) by default, except the request
+	// carries ctx so a caller can give up on a slow server
+	// instead of log.Fatal()ing the whole program when something goes wrong,
+	// we hand the error back to our caller and let them decide what to do
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, env.robotsURL(), nil)
 	if err != nil {
-		log.Fatal(err)
+		return lessonError("DeferPanicRecover", ErrFetch, "building robots.txt request", err)
+	}
+	res, err := env.fetcher().Do(req)
+	if err != nil {
+		return lessonError("DeferPanicRecover", ErrFetch, "fetching robots.txt", err)
 	}
 	defer res.Body.Close() // we will always close this resource no matter
-	// what throws us from this function
+	// what throws us from this function, including those early returns
+	if res.StatusCode != http.StatusOK {
+		return lessonError("DeferPanicRecover", ErrFetch, "fetching robots.txt", fmt.Errorf("unexpected status %s", res.Status))
+	}
 	robots, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		log.Fatal(err)
+		return lessonError("DeferPanicRecover", ErrFetch, "reading robots.txt", err)
 	}
 
-	fmt.Printf("%s", robots)
+	fmt.Fprintf(w, "%s", robots)
 
 	// one more thing to note is that if an argument is passed to a deferred
 	// function, the argument that is used is the one seen AT DEFERED CALL
@@ -870,7 +985,7 @@ func DeferPanicRecover() string {
 	// stack by the Go runtime when the defer call is made
 
 	a := "i will be printed"
-	defer fmt.Println(a)
+	defer fmt.Fprintln(w, a)
 	a = "i will not be printed"
 
 	// PANIC
@@ -904,14 +1019,14 @@ func DeferPanicRecover() string {
 	// this will then recover, so the panic will not propogate
 	// all the way up into this calling function
 	// to this function, execution continues normally
-	fmt.Println("start")
-	panicker()
-	fmt.Println("end")
+	fmt.Fprintln(w, "start")
+	panicker(w, env)
+	fmt.Fprintln(w, "end")
 
 	// we basically can only use recover() in a deferred context
 	// if we want to recover inside (when leaving) the throwing function
 
-	return "DeferPanicRecover"
+	return nil
 }
 
 type basicStruct struct {
@@ -920,19 +1035,20 @@ type basicStruct struct {
 }
 
 // Pointers shows how pointers work in go... wow these are getting worse and worse
-func Pointers() string {
-	fmt.Println("\nShowing Pointers Basics in Go...")
+func Pointers(w io.Writer, env *Env) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing Pointers Basics in Go...")
 
 	// pointers are basically the same to C-Like languages
 	a := 42
-	b := &a         // b is a pointer to the address of a
-	var c int = 42  // equivalent to above
-	var d *int = &c // equivalent to above
-	fmt.Println(c, d)
-	fmt.Println(a)
+	b := &a                         // b is a pointer to the address of a
+	var c int = 42                  // equivalent to above
+	var d *int = &c                 // equivalent to above
+	fmt.Fprintln(w, c, env.addr(d)) // printing a pointer shows its address
+	fmt.Fprintln(w, a)
 
-	*b = 21        // "assign value pointed to by b to 21"
-	fmt.Println(a) // a will be  modified since b pointed to a
+	*b = 21            // "assign value pointed to by b to 21"
+	fmt.Fprintln(w, a) // a will be  modified since b pointed to a
 
 	// what about pointer arithmetic?
 	// CANT DO IT...
@@ -940,13 +1056,13 @@ func Pointers() string {
 	arr := [3]int{1, 2, 3}
 	b = &arr[0]
 	d = &arr[1]
-	fmt.Printf("%v %p %p\n", arr, b, d) // %p is pointer
+	fmt.Fprintf(w, "%v %s %s\n", arr, env.addr(b), env.addr(d)) // env.addr prints like %p, which is pointer
 
 	var sp *basicStruct
 	sp = &basicStruct{foo: 1, bar: 2}
 	sp = new(basicStruct) // cannot use the init list syntax
 	// will be created with default values
-	fmt.Println(sp)
+	fmt.Fprintln(w, sp)
 	// to do assignment of fields from a pointer to a struct need to use this
 	// (*NAME).field = VALUE syntax
 	// because the () operation takes precedence over the . operator
@@ -964,7 +1080,7 @@ func Pointers() string {
 	// what is the zero-value for a pointer????
 	// NIL ... aka nullptr
 
-	return "Pointers"
+	return nil
 }
 
 // we can provide arguments in this list style
@@ -984,13 +1100,13 @@ type bigBoy struct {
 	hugeArr [1000]int
 }
 
-func takeStructByRef(pBigBoy *bigBoy) {
-	fmt.Println("Working on the big boy.")
+func takeStructByRef(w io.Writer, pBigBoy *bigBoy) {
+	fmt.Fprintln(w, "Working on the big boy.")
 	for k, v := range pBigBoy.bigArr {
 		v = k + k%2
 		pBigBoy.hugeArr[k] = v
 		if k%10 == 0 {
-			fmt.Println(k)
+			fmt.Fprintln(w, k)
 		}
 	}
 }
@@ -999,9 +1115,10 @@ func takeStructByRef(pBigBoy *bigBoy) {
 // when passed, they act as a SLICE!!!
 // can only have one variadic parameter, and it has to be LAST in the args list
 // nothing stopping you from writing:
-// 		func sum2(msg string, otherVal int, values ... int) int {}
-func sum(values ...int) int {
-	fmt.Println(values)
+//
+//	func sum2(msg string, otherVal int, values ... int) int {}
+func sum(w io.Writer, values ...int) int {
+	fmt.Fprintln(w, values)
 	result := 0
 	for _, v := range values {
 		result += v
@@ -1019,12 +1136,12 @@ func sum(values ...int) int {
 // for you
 // alternatively, just allocate pointers to the heap within functions
 // so you are never confused by them
-func sumReturnPointer(values ...int) *int {
+func sumReturnPointer(w io.Writer, values ...int) *int {
 	result := 0
 	for _, v := range values {
 		result += v
 	}
-	fmt.Println("Moving stack variable to the heap")
+	fmt.Fprintln(w, "Moving stack variable to the heap")
 	return &result
 }
 
@@ -1084,17 +1201,17 @@ type greeter struct {
 // takes by value with this style
 // therefore modifications make no side effects
 // (g greeter) is called a VALUE RECIEVER
-func (g greeter) greetVal() {
+func (g greeter) greetVal(w io.Writer) {
 	g.greeting = "Goodbye"
-	fmt.Println(g.greeting, g.name)
+	fmt.Fprintln(w, g.greeting, g.name)
 }
 
 // takes by reference (pointer) with this style
 // therefore, modifications make side effects
 // (g *greeter) is called a REFERENCE RECIEVER
-func (g *greeter) greetRef() {
+func (g *greeter) greetRef(w io.Writer) {
 	g.greeting = "Goodbye"
-	fmt.Println(g.greeting, g.name)
+	fmt.Fprintln(w, g.greeting, g.name)
 }
 
 // VAL vs REF recievers
@@ -1110,8 +1227,9 @@ func (g *greeter) greetRef() {
 // that actually kinda blows
 
 // Functions shows basic syntax, parameters, returns, anonymous funcs, function as types, methods
-func Functions() string {
-	fmt.Println("\nShowing Functions Basics in Go...")
+func Functions(w io.Writer) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing Functions Basics in Go...")
 
 	// ok we know the basic syntax already
 	// func NAME(param1 type, param2 type ...) returntype {}
@@ -1119,40 +1237,40 @@ func Functions() string {
 	// extremely supid example alert
 	a := multiArgSameType(1, 1, 1, 1, 1, 1, 1)
 	// get the value pointed to by &a back and print
-	fmt.Println("Before pass by reference", a)
+	fmt.Fprintln(w, "Before pass by reference", a)
 	pointerAcceptor(&a)
-	fmt.Println("Should print zero:", a)
+	fmt.Fprintln(w, "Should print zero:", a)
 
 	// big boy is a heavy weight struct with two large arrays in it
 	// imaging passing this struct by value and having to copy everything
 	// alternatively we can just pass a pointer which is just 8 bytes
 	// (64-bit addresses on 64-bit machines)
 	pBoy := new(bigBoy)
-	takeStructByRef(pBoy)
+	takeStructByRef(w, pBoy)
 
-	sum := sum(1, 2, 2, 3, 55, 11, 6, 2, 2, 52, 3, 52)
-	fmt.Println("Sum from variadic function args func is:", sum)
-	psum := sumReturnPointer(1, 2, 2, 3, 55, 11, 6, 2, 2, 52, 3, 52)
-	fmt.Println("Sum from variadic function args func with pointer on stack moved to the heap is is:", *psum)
+	sum := sum(w, 1, 2, 2, 3, 55, 11, 6, 2, 2, 52, 3, 52)
+	fmt.Fprintln(w, "Sum from variadic function args func is:", sum)
+	psum := sumReturnPointer(w, 1, 2, 2, 3, 55, 11, 6, 2, 2, 52, 3, 52)
+	fmt.Fprintln(w, "Sum from variadic function args func with pointer on stack moved to the heap is is:", *psum)
 	psum = sumReturnPointer2(1, 2, 2, 3, 55, 11, 6, 2, 2, 52, 3, 52)
-	fmt.Println("Sum from variadic function args func with heap pointer return is is:", *psum)
+	fmt.Fprintln(w, "Sum from variadic function args func with heap pointer return is is:", *psum)
 	sum = sumReturnPointer4(1, 2, 2, 3, 55, 11, 6, 2, 2, 52, 3, 52)
-	fmt.Println("Sum from variadic function args func with named return is is:", sum)
+	fmt.Fprintln(w, "Sum from variadic function args func with named return is is:", sum)
 
 	val, err := multiReturnVal(1.0, 0.0) // this will return an error since it divides by 0
 	if err != nil {
-		fmt.Println(val, err.Error())
+		fmt.Fprintln(w, val, err.Error())
 	}
 
 	// anonymous functions
 	// anything you can do with any other type, you can do with functions in Go
 	// FIRST CLASS CITIZENSHIP
 	func() {
-		fmt.Println("I am an invoked anonymous function")
+		fmt.Fprintln(w, "I am an invoked anonymous function")
 	}()
 
 	f := func() {
-		fmt.Println("I am a an anonymous function saved to a var")
+		fmt.Fprintln(w, "I am a an anonymous function saved to a var")
 	}
 	// invoke
 	f()
@@ -1162,7 +1280,7 @@ func Functions() string {
 	// this anonymous func captures "i" since it is part of the current context
 	for i := 0; i < 5; i++ {
 		func() {
-			fmt.Println(i)
+			fmt.Fprintln(w, i)
 		}()
 	}
 
@@ -1170,7 +1288,7 @@ func Functions() string {
 	// explicitly as shown below:
 	for i := 0; i < 5; i++ {
 		func(val int) {
-			fmt.Println(val)
+			fmt.Fprintln(w, val)
 		}(i)
 	}
 
@@ -1185,9 +1303,9 @@ func Functions() string {
 
 	d, err := div(5, 4)
 	if err != nil {
-		fmt.Println(err.Error())
+		fmt.Fprintln(w, err.Error())
 	}
-	fmt.Println(d)
+	fmt.Fprintln(w, d)
 
 	// methods
 	// are only declared outside of structs in Go
@@ -1199,12 +1317,12 @@ func Functions() string {
 	}
 	// invoking the method has a standard syntax
 	// whether it is calling by val or reference
-	g.greetVal()
-	fmt.Println(g) // should be unmodified
-	g.greetRef()
-	fmt.Println(g) // should be modified
+	g.greetVal(w)
+	fmt.Fprintln(w, g) // should be unmodified
+	g.greetRef(w)
+	fmt.Fprintln(w, g) // should be modified
 
-	return "Functions"
+	return nil
 }
 
 // interfaces are types just like structs
@@ -1230,31 +1348,23 @@ type Writer interface {
 // we generally create structs and then have them satisfy interfaces
 
 // ConsoleWriter is a Writer to the ouput console
-type ConsoleWriter struct{}
-
-// TCPWriter is a Writer to a TCP connection
-type TCPWriter struct{}
+type ConsoleWriter struct {
+	// Out is the console to write to, os.Stdout when nil
+	Out io.Writer
+}
 
-// FileWriter is a Writer to a File
-type FileWriter struct{}
+// TCPWriter (see tcpwriter.go) is a Writer to a real TCP connection
+// and FileWriter (see filewriter.go) is a Writer to a real file
 
 // we only implictly satisfy interfaces
 // by creating their implementations for our structs
 func (cw ConsoleWriter) Write(data []byte) (int, error) {
-	n, err := fmt.Println(string(data))
-	return n, err
-}
-
-func (tw TCPWriter) Write(data []byte) (int, error) {
-	fmt.Println("shhh pretend im writing to a TCP connection")
-	n, err := fmt.Println(string(data))
-	return n, err
-}
-
-func (fw FileWriter) Write(data []byte) (int, error) {
-	fmt.Println("shhh pretend im writing to a file")
-	n, err := fmt.Println(string(data))
-	return n, err
+	// Fprintln adds a newline, which isn't one of data's bytes,
+	// so report how much of data we wrote rather than what Fprintln did
+	if _, err := fmt.Fprintln(output(cw.Out), string(data)); err != nil {
+		return 0, err
+	}
+	return len(data), nil
 }
 
 // note since all types are treated equally, we can do something like this:
@@ -1286,89 +1396,93 @@ type WriterCloser interface {
 	Closer
 }
 
-// BufferedWriterCloser has a buffer and writes/closes
-type BufferedWriterCloser struct {
-	buffer *bytes.Buffer
-}
+// Interfaces are contracts that a struct must fulfil (generally in implementing some kind of method)
+func Interfaces(w io.Writer, env *Env) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing Interfaces Basics in Go...")
 
-func (bwc *BufferedWriterCloser) Write(data []byte) (int, error) {
-	n, err := bwc.buffer.Write(data)
-	if err != nil {
-		return 0, err
+	// we can create a variable that is of an interface type
+	var cw Writer = ConsoleWriter{Out: w}
+	if _, err := cw.Write([]byte("Using a Writer interface!")); err != nil {
+		return lessonError("Interfaces", ErrOutput, "writing to a ConsoleWriter", err)
 	}
 
-	v := make([]byte, 8)
-	for bwc.buffer.Len() > 8 {
-		_, err := bwc.buffer.Read(v)
-		if err != nil {
-			return 0, err
-		}
-
-		_, err = fmt.Println(string(v))
-		if err != nil {
-			return 0, err
-		}
+	// a TCPWriter really dials out, so give it a server on localhost
+	// that hangs on to whatever it is sent
+	sink, err := newTCPSink()
+	if err != nil {
+		return lessonError("Interfaces", ErrOutput, "starting a tcp server", err)
 	}
-	return n, nil
-}
+	defer sink.Close()
+	tw := NewTCPWriter(sink.Addr())
+	defer tw.Close() // deferred calls run last in first out, so this runs first
 
-// Close goes thru the whole buffer and flushes in 8 byte chunks
-func (bwc *BufferedWriterCloser) Close() error {
-	for bwc.buffer.Len() > 0 {
-		data := bwc.buffer.Next(8)
-		_, err := fmt.Println(string(data))
-		if err != nil {
-			return err
-		}
+	// and a FileWriter really writes a file, so give it a scratch dir
+	dir, err := ioutil.TempDir("", "golearn-interfaces")
+	if err != nil {
+		return lessonError("Interfaces", ErrFilesystem, "creating scratch dir", err)
 	}
-	return nil
-}
-
-// NewBufferedWriterCloser makes a new buffered writercloser
-func NewBufferedWriterCloser() *BufferedWriterCloser {
-	fmt.Println("Creating new BufferedWriterCloser object")
-	bwc := new(BufferedWriterCloser)
-	bwc.buffer = bytes.NewBuffer([]byte{})
-	return bwc
-}
-
-// Interfaces are contracts that a struct must fulfil (generally in implementing some kind of method)
-func Interfaces() string {
-	fmt.Println("\nShowing Interfaces Basics in Go...")
-
-	// we can create a variable that is of an interface type
-	var w Writer = ConsoleWriter{}
-	w.Write([]byte("Using a Writer interface!"))
+	defer os.RemoveAll(dir)
+	fw := NewFileWriter(filepath.Join(dir, "interfaces.log"))
+	defer fw.Close()
 
 	// we can create an array of structs
 	// that satisfy the same interface
 	// and call methods on all of them
-	writers := [3]Writer{ConsoleWriter{}, TCPWriter{}, FileWriter{}}
+	writers := [3]Writer{ConsoleWriter{Out: w}, tw, fw}
+
+	for _, writer := range writers {
+		if _, err := writer.Write([]byte("Using a Writer interface!")); err != nil {
+			return lessonError("Interfaces", ErrOutput, fmt.Sprintf("writing to a %T", writer), err)
+		}
+	}
 
-	for _, w := range writers {
-		w.Write([]byte("Using a Writer interface!"))
+	// a MultiWriter is a Writer too, one that hands everything it gets
+	// to the writers inside it, so one Write reaches all three
+	// and one Close closes the ones that are Closers as well
+	mw := NewMultiWriter(writers[:]...)
+	if _, err := mw.Write([]byte("Using a MultiWriter!")); err != nil {
+		return lessonError("Interfaces", ErrOutput, "writing to a MultiWriter", err)
+	}
+	if err := mw.Close(); err != nil {
+		return lessonError("Interfaces", ErrOutput, "closing a MultiWriter", err)
 	}
 
+	// closing the TCPWriter hung up, and once everyone has hung up
+	// the server can tell us what it got
+	fmt.Fprintf(w, "TCP server received: %q\n", sink.Close())
+	data, err := ioutil.ReadFile(fw.Path)
+	if err != nil {
+		return lessonError("Interfaces", ErrFilesystem, "reading back a FileWriter's file", err)
+	}
+	fmt.Fprintf(w, "File contains: %q\n", data)
+
 	var ic IntCounter = 0
 
 	for i := 0; i < 20; i++ {
 		ic.Increment()
 	}
 
-	fmt.Println(int(ic)) // should be 20 right?
+	fmt.Fprintln(w, int(ic)) // should be 20 right?
 
-	var wc WriterCloser = NewBufferedWriterCloser() // define as an interface
-	wc.Write([]byte("What is up boys, please like and subscribe!"))
-	wc.Close()
+	// the BufferedWriterCloser hands its 8 byte chunks to a ConsoleWriter,
+	// which prints each one on a line of its own
+	var wc WriterCloser = NewBufferedWriterCloser(ConsoleWriter{Out: w}, 8) // define as an interface
+	if _, err := wc.Write([]byte("What is up boys, please like and subscribe!")); err != nil {
+		return lessonError("Interfaces", ErrOutput, "writing to a WriterCloser", err)
+	}
+	if err := wc.Close(); err != nil {
+		return lessonError("Interfaces", ErrOutput, "closing a WriterCloser", err)
+	}
 
 	// type conversion
 	// this will work because BufferedWriterCloser
 	// fulfulls this interface
 	bwc, ok := wc.(*BufferedWriterCloser) // now convert to struct
 	if ok {
-		fmt.Println(bwc)
+		fmt.Fprintf(w, "%T at %s\n", bwc, env.addr(bwc))
 	} else {
-		fmt.Println("Conversion Failed")
+		fmt.Fprintln(w, "Conversion Failed")
 	}
 
 	// we can try to convert the WriterCloser to an io.Reader
@@ -1376,13 +1490,13 @@ func Interfaces() string {
 	// we can add some error checking so we don't panic out of execution
 	r, ok := wc.(io.Reader)
 	if ok {
-		fmt.Println(r)
+		fmt.Fprintln(w, r)
 	} else {
-		fmt.Println("Conversion Failed")
+		fmt.Fprintln(w, "Conversion Failed")
 	}
 
 	// we can use something called the empty interface
-	var empty interface{} = NewBufferedWriterCloser()
+	var empty interface{} = NewBufferedWriterCloser(ConsoleWriter{Out: w}, 8)
 	// what is the point of this thing?
 	// to do anything useful with it, we need to convert it to some other interface
 	// as shown below
@@ -1390,8 +1504,12 @@ func Interfaces() string {
 	// or we can use the reflect package to do some slick
 	// type switching to determine what to use
 	if conv, ok := empty.(WriterCloser); ok {
-		conv.Write([]byte("Writing into interface converted from empty interface"))
-		conv.Close()
+		if _, err := conv.Write([]byte("Writing into interface converted from empty interface")); err != nil {
+			return lessonError("Interfaces", ErrOutput, "writing to a converted WriterCloser", err)
+		}
+		if err := conv.Close(); err != nil {
+			return lessonError("Interfaces", ErrOutput, "closing a converted WriterCloser", err)
+		}
 	}
 
 	// lets look at empty interfaces with type switching
@@ -1401,11 +1519,11 @@ func Interfaces() string {
 	var i interface{} = 0
 	switch i.(type) {
 	case int:
-		fmt.Println("i is an int")
+		fmt.Fprintln(w, "i is an int")
 	case string:
-		fmt.Println("i is a string")
+		fmt.Fprintln(w, "i is a string")
 	default:
-		fmt.Println("idk what i is...")
+		fmt.Fprintln(w, "idk what i is...")
 	}
 
 	// Best Practices with Interfaces
@@ -1419,60 +1537,71 @@ func Interfaces() string {
 
 	// Design functions and methods to recieve interfaces whenever possible
 
-	return "Interfaces"
+	return nil
 }
 
-func printMsg(msg string) {
-	fmt.Println(msg)
-	wg.Done()
+// goroutineState is everything the goroutines in GoRoutines share
+// every run of the lesson makes its own, so two runs at once (or one
+// after another) never see each other's counter
+type goroutineState struct {
+	// wait group is like a list of pending go routines
+	// we can use it to wait for execution of a spawned goroutine
+	// from a "main" thread
+	wg        sync.WaitGroup
+	wgCounter int
+	m         sync.RWMutex
 }
 
-// wait group is like a list of pending go routines
-// we can use it to wait for execution of a spawned goroutine
-// from a "main" thread
-var wg = sync.WaitGroup{}
-var wgCounter = 0
-var m = sync.RWMutex{}
+func (s *goroutineState) printMsg(w io.Writer, msg string) {
+	fmt.Fprintln(w, msg)
+	s.wg.Done()
+}
 
-func increment() {
-	wgCounter++
-	wg.Done()
+func (s *goroutineState) increment() {
+	s.wgCounter++
+	s.wg.Done()
 }
 
-func printCounter() {
-	fmt.Println("counter:", wgCounter)
-	wg.Done()
+func (s *goroutineState) printCounter(w io.Writer) {
+	fmt.Fprintln(w, "counter:", s.wgCounter)
+	s.wg.Done()
 }
 
-func incrementWithMutex() {
-	m.Lock() // lock mutex (read + write)
-	wgCounter++
-	m.Unlock()
-	wg.Done()
+func (s *goroutineState) incrementWithMutex() {
+	s.m.Lock() // lock mutex (read + write)
+	s.wgCounter++
+	s.m.Unlock()
+	s.wg.Done()
 }
 
-func printCounterWithMutex() {
-	m.RLock() // read lock the mutex
-	fmt.Println("counter:", wgCounter)
-	m.RUnlock()
-	wg.Done()
+func (s *goroutineState) printCounterWithMutex(w io.Writer) {
+	s.m.RLock() // read lock the mutex
+	fmt.Fprintln(w, "counter:", s.wgCounter)
+	s.m.RUnlock()
+	s.wg.Done()
 }
 
-func incrementWithMutex2() {
-	wgCounter++
-	m.Unlock()
-	wg.Done()
+func (s *goroutineState) incrementWithMutex2() {
+	s.wgCounter++
+	s.m.Unlock()
+	s.wg.Done()
 }
 
-func printCounterWithMutex2() {
-	fmt.Println("counter:", wgCounter)
-	m.RUnlock()
-	wg.Done()
+func (s *goroutineState) printCounterWithMutex2(w io.Writer) {
+	fmt.Fprintln(w, "counter:", s.wgCounter)
+	s.m.RUnlock()
+	s.wg.Done()
 }
 
+// GOMAXPROCS is process wide rather than per run, so runs of GoRoutines
+// take turns changing it and put it back the way they found it
+var maxProcsMu sync.Mutex
+
 // GoRoutines details Go's lightweight process, the goroutine
-func GoRoutines() string {
-	fmt.Println("\nShowing GoRoutine Basics in Go...")
+func GoRoutines(ctx context.Context, w io.Writer) error {
+	w = &lockedWriter{w: output(w)} // lots of goroutines print at once
+	s := new(goroutineState)
+	fmt.Fprintln(w, "\nShowing GoRoutine Basics in Go...")
 
 	// instead of using OS threads
 	// Go uses lightweight processes (user-space threads)
@@ -1482,85 +1611,112 @@ func GoRoutines() string {
 	// go routines are just abstractions of these user threads
 	// the go runtime maps go routines onto the actual OS threads for us
 
-	wg.Add(3)
-	go printMsg("I am running as a go routine")
+	s.wg.Add(2)
+	go s.printMsg(w, "I am running as a go routine")
 
 	go func() {
-		printMsg("I am a go routine running an anonymous function")
+		s.printMsg(w, "I am a go routine running an anonymous function")
 	}()
 
 	msg := "I am a message given to anon func"
-	go func() {
-		printMsg(msg) // this will be a different thread,
-		// but the Go runtime will still know where to access msg at
-		// we have introduced a dependency from this master thread
-		// and this go routine tho, so it is starting to get spicy
-	}()
+	// this goroutine races with the assignment below on purpose,
+	// which the race detector rightly reports, so -race builds skip it
+	if !raceEnabled {
+		s.wg.Add(1)
+		go func() {
+			s.printMsg(w, msg) // this will be a different thread,
+			// but the Go runtime will still know where to access msg at
+			// we have introduced a dependency from this master thread
+			// and this go routine tho, so it is starting to get spicy
+		}()
+	}
 	msg = "I am a messaged that changed after the go routine call"
 	// we might get the inital msg value or this second value
 	// no real way to know, undefined behavior
-	wg.Wait()
+	// wait is s.wg.Wait() that gives up if whoever ran us stops waiting
+	if err := wait(ctx, &s.wg); err != nil {
+		return lessonError("GoRoutines", ErrCanceled, "waiting for goroutines", err)
+	}
 
 	// so generally, we do not want to play around with this closure stuff
 	// we could rewrite the function as one that takes the msg by value
 	// and resolve this issue
 
-	fmt.Println("Trying again but passing message to goroutine by value")
+	fmt.Fprintln(w, "Trying again but passing message to goroutine by value")
 
-	wg.Add(1)
+	s.wg.Add(1)
 	// reset
 	msg = "I am a message given to anon func"
 	go func(msg string) {
-		printMsg(msg) // passed this goroutine a value, so problem solved
+		s.printMsg(w, msg) // passed this goroutine a value, so problem solved
 	}(msg)
 	msg = "I am a messaged that changed after the go routine call"
-	wg.Wait() // waiting for signal
+	if err := wait(ctx, &s.wg); err != nil { // waiting for signal
+		return lessonError("GoRoutines", ErrCanceled, "waiting for goroutines", err)
+	}
 
-	fmt.Println("Unsyncronized goroutines...")
+	fmt.Fprintln(w, "Unsyncronized goroutines...")
 	// this loop will spawn 20 goroutines
 	// but we will have no sync BETWEEN ROUTINES
-	for i := 0; i < 10; i++ {
-		wg.Add(2)
-		go printCounter()
-		go increment()
+	// that is a data race, so again -race builds skip it
+	if raceEnabled {
+		fmt.Fprintln(w, "(skipped, the race detector would report it)")
+		s.wgCounter += 10
+	} else {
+		for i := 0; i < 10; i++ {
+			s.wg.Add(2)
+			go s.printCounter(w)
+			go s.increment()
+		}
+		if err := wait(ctx, &s.wg); err != nil { // wait until they are all done
+			return lessonError("GoRoutines", ErrCanceled, "waiting for goroutines", err)
+		}
 	}
-	wg.Wait() // wait until they are all done
 
-	fmt.Println("Syncronized goroutines with mutexes...")
+	fmt.Fprintln(w, "Syncronized goroutines with mutexes...")
 	for i := 0; i < 10; i++ {
-		wg.Add(2)
-		go printCounterWithMutex()
-		go incrementWithMutex()
+		s.wg.Add(2)
+		go s.printCounterWithMutex(w)
+		go s.incrementWithMutex()
+	}
+	if err := wait(ctx, &s.wg); err != nil { // wait until they are all done
+		return lessonError("GoRoutines", ErrCanceled, "waiting for goroutines", err)
 	}
-	wg.Wait() // wait until they are all done
 
-	fmt.Println("Syncronized goroutines with mutexes outside of calls...")
+	fmt.Fprintln(w, "Syncronized goroutines with mutexes outside of calls...")
 	// now that we are locking the mutexes in the SAME context
 	// what happens is guaranteed to be ORDERED
 	for i := 0; i < 10; i++ {
-		wg.Add(2)
-		m.RLock()
-		go printCounterWithMutex2()
-		m.Lock()
-		go incrementWithMutex2()
+		s.wg.Add(2)
+		s.m.RLock()
+		go s.printCounterWithMutex2(w)
+		s.m.Lock()
+		go s.incrementWithMutex2()
+	}
+	if err := wait(ctx, &s.wg); err != nil { // wait until they are all done
+		return lessonError("GoRoutines", ErrCanceled, "waiting for goroutines", err)
 	}
-	wg.Wait() // wait until they are all done
 
 	// this basically is making everything be single threaded tho...
 	// great
 	// single threaded + mutex overhead = worse than original
 
 	// the runtime packages lets us query things like max number of threads
-	fmt.Println("GOMAXPROCS:", runtime.GOMAXPROCS(-1))
+	// (and the lock makes sure we put it back before anyone else looks)
+	maxProcsMu.Lock()
+	defer maxProcsMu.Unlock()
+	oldMaxProcs := runtime.GOMAXPROCS(-1)
+	defer runtime.GOMAXPROCS(oldMaxProcs)
+	fmt.Fprintln(w, "GOMAXPROCS:", oldMaxProcs)
 
 	// think of GOMAXPROCS as a tuning parameter for your
 	// parallel applications
 
 	// minimum 1 thread per core
 	newMaxProcs := 100
-	fmt.Println("Setting GOMAXPROCS to", newMaxProcs)
+	fmt.Fprintln(w, "Setting GOMAXPROCS to", newMaxProcs)
 	runtime.GOMAXPROCS(newMaxProcs)
-	fmt.Println("GOMAXPROCS:", runtime.GOMAXPROCS(-1))
+	fmt.Fprintln(w, "GOMAXPROCS:", runtime.GOMAXPROCS(-1))
 
 	// this is the # of OS threads, so just creating a TON
 	// will add lots of memory overhead
@@ -1580,43 +1736,14 @@ func GoRoutines() string {
 	// 		"go build -msan" will do address sanitizer
 	//		"go run -race"  will actually run and print stack trace for us
 
-	return "GoRoutines"
-}
-
-const (
-	logInfo    = "INFO"
-	logWarning = "WARNING"
-	logError   = "ERROR"
-)
-
-type logEntry struct {
-	time     time.Time
-	severity string
-	message  string
-}
-
-var logCh = make(chan logEntry, 50)
-var doneCh = make(chan struct{}) // empty struct
-// this is what is known as a signal only channel
-// so it is some kind of semaphore stuff
-
-// we now have a select{} control block in this function
-// select multiplexes incoming signals
-func logger() {
-	for {
-		select {
-		case entry := <-logCh:
-			fmt.Printf("%v - [%v]%v\n", entry.time.Format("2006-01-02T15:04:05"), entry.severity, entry.message)
-
-		case <-doneCh:
-			break
-		}
-	}
+	return nil
 }
 
 // Channels are how we can pass data between threads in go
-func Channels() string {
-	fmt.Println("\nShowing Channels Basics in Go...")
+func Channels(ctx context.Context, w io.Writer, env *Env) error {
+	w = &lockedWriter{w: output(w)} // lots of goroutines print at once
+	var wg sync.WaitGroup           // each run waits on its own goroutines
+	fmt.Fprintln(w, "\nShowing Channels Basics in Go...")
 	// channels are pretty much always going to be used in the context
 	// of goroutines, since it is for passing data between threads
 	// so, they build off of goroutines, and are what truly make them
@@ -1645,7 +1772,7 @@ func Channels() string {
 	wg.Add(2)
 	go func() {
 		i := <-ch // recieve from channel
-		fmt.Println(i)
+		fmt.Fprintln(w, i)
 		wg.Done()
 	}()
 
@@ -1653,7 +1780,9 @@ func Channels() string {
 		ch <- 42 // send on channel
 		wg.Done()
 	}()
-	wg.Wait()
+	if err := wait(ctx, &wg); err != nil {
+		return lessonError("Channels", ErrCanceled, "waiting for goroutines", err)
+	}
 
 	// we need a RECV for every SEND
 	// otherwise we will get a deadlock and crash
@@ -1672,12 +1801,12 @@ func Channels() string {
 	// if we tried to do a SEND in the func that took a RECV chan
 	// we would get a compile time error
 	// this gives us more type safety, and encourages modularized designs
-	fmt.Println("Unidirectional Channels in a loop:")
+	fmt.Fprintln(w, "Unidirectional Channels in a loop:")
 	for j := 0; j < 5; j++ {
 		wg.Add(2)
 		go func(ch <-chan int, j int) { // takes a RECV channel only
 			i := <-ch // recieve from channel
-			fmt.Println(i)
+			fmt.Fprintln(w, i)
 			wg.Done()
 		}(ch, j) // note we are inputting a bidirectional chan
 		// and the compiler will just note that it is RECV only
@@ -1687,7 +1816,9 @@ func Channels() string {
 			wg.Done()
 		}(ch, j)
 	}
-	wg.Wait()
+	if err := wait(ctx, &wg); err != nil {
+		return lessonError("Channels", ErrCanceled, "waiting for goroutines", err)
+	}
 
 	// BUFFERED CHANNELS
 	// with buffered channels, we now have non-blocking behavior!
@@ -1695,12 +1826,12 @@ func Channels() string {
 	// below, we have  buffer of size 2, so both messages can be
 	// SENT without blocking, but we only read one out then leave
 	// the goroutine, essentially losing the 45 value on the channel
-	fmt.Println("Sending 2 values to buffered channel (size 2)")
+	fmt.Fprintln(w, "Sending 2 values to buffered channel (size 2)")
 	ch = make(chan int, 2)
 	wg.Add(2)
 	go func(ch <-chan int) {
 		i := <-ch // recieve from channel
-		fmt.Println(i)
+		fmt.Fprintln(w, i)
 		wg.Done()
 	}(ch)
 
@@ -1709,19 +1840,21 @@ func Channels() string {
 		ch <- 45 // send on channel
 		wg.Done()
 	}(ch)
-	wg.Wait()
+	if err := wait(ctx, &wg); err != nil {
+		return lessonError("Channels", ErrCanceled, "waiting for goroutines", err)
+	}
 
 	// iterate over buffered channel to process all data
 	chanSize := 50
 	sendSize := chanSize - 5
 	ch = make(chan int, chanSize)
-	fmt.Printf("Sending %d values to buffered channel (size %d)\n", sendSize, chanSize)
+	fmt.Fprintf(w, "Sending %d values to buffered channel (size %d)\n", sendSize, chanSize)
 
 	wg.Add(2)
 	go func(ch <-chan int) {
 		for {
 			if val, ok := <-ch; ok {
-				fmt.Println(val)
+				fmt.Fprintln(w, val)
 			} else {
 				break
 			}
@@ -1742,93 +1875,102 @@ func Channels() string {
 		// we have to make a new channel now
 		wg.Done()
 	}(ch)
-	wg.Wait()
-
-	//fmt.Println("Starting Logger....")
-	//go logger()
-
-	//logCh <- logEntry{time.Now(), logInfo, "App is starting"}
-	//time.Sleep(100 * time.Millisecond)
-
-	//logCh <- logEntry{time.Now(), logInfo, "App is shutting down"}
-	//time.Sleep(100 * time.Millisecond)
+	if err := wait(ctx, &wg); err != nil {
+		return lessonError("Channels", ErrCanceled, "waiting for goroutines", err)
+	}
 
-	//doneCh <- struct{}{} // this syntax is kind of jank
-	// but this is how you send a blank semaphore in Go
+	// a logger is the classic use for all of this: whoever logs sends the
+	// entry down a buffered channel, and one goroutine selects between
+	// that channel and a done channel, writing entries until it is told
+	// to stop, see logger.go for the whole thing
+	fmt.Fprintln(w, "Starting Logger....")
+	log := NewLogger(w, LoggerConfig{Buffer: 50, Now: env.now})
+	log.Info("App is starting")
+	log.Info("App is shutting down")
+	// Close signals done and waits for the logger to write what is left,
+	// so unlike a time.Sleep() we know nothing is lost
+	if err := log.Close(); err != nil {
+		return lessonError("Channels", ErrOutput, "closing logger", err)
+	}
 
-	return "Channels"
+	return nil
 }
 
-func prepaterTestDirTree(tree string) (string, error) {
+func prepaterTestDirTree(w io.Writer, tree string) (string, error) {
 	tmpDir, err := ioutil.TempDir("", "")
 	if err != nil {
-		return "", fmt.Errorf("error creating temp directory: %v\n", err)
+		return "", fmt.Errorf("error creating temp directory: %w", err)
 	}
 
 	err = os.MkdirAll(filepath.Join(tmpDir, tree), 0755)
 	if err != nil {
 		os.RemoveAll(tmpDir)
-		return "", nil
+		return "", fmt.Errorf("error creating %s: %w", tree, err)
 	}
 
-	fmt.Printf("Temp dir to walk: %s\n", tree)
+	fmt.Fprintf(w, "Temp dir to walk: %s\n", tree)
 	return tmpDir, nil
 }
 
 // Filepath shows functionality of the "path/filepath" Go library package
-func Filepath() string {
-	fmt.Println("\nShowing path/filepath Basics in Go...")
+func Filepath(w io.Writer, env *Env) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing path/filepath Basics in Go...")
 
-	fmt.Printf("os.PathSeparator:\t%s\n", string(os.PathSeparator))
-	fmt.Printf("os.PathListSeparator:\t%s\n", string(os.PathListSeparator))
+	fmt.Fprintf(w, "os.PathSeparator:\t%s\n", string(os.PathSeparator))
+	fmt.Fprintf(w, "os.PathListSeparator:\t%s\n", string(os.PathListSeparator))
 
 	myPath := "."
-	myAbsPath, err := filepath.Abs(myPath)
+	// filepath.Abs(myPath) is filepath.Join(os.Getwd(), myPath)
+	// we ask env for the working dir so deterministic runs all agree on it
+	wd, err := env.getwd()
 	if err != nil {
-		fmt.Errorf("Could not get absolute path")
+		return lessonError("Filepath", ErrFilesystem, "getting absolute path", err)
 	}
+	myAbsPath := filepath.Join(wd, myPath)
 
-	fmt.Printf("My relative path:\t%s\n", myPath)
-	fmt.Printf("My absolute path:\t%s\n", myAbsPath)
+	fmt.Fprintf(w, "My relative path:\t%s\n", myPath)
+	fmt.Fprintf(w, "My absolute path:\t%s\n", myAbsPath)
 
 	// Base takes the bottom (right-most)
 	// for example, if my path is /foo/bar
 	// the base is bar
 	testBase := filepath.Base(myAbsPath)
-	fmt.Printf("My base directory:\t%s\n", testBase)
+	fmt.Fprintf(w, "My base directory:\t%s\n", testBase)
 
 	// we can use Join() to combine path elements with the PathSeparator
 	// Join()ed paths are auto-cleaned
 	wonkyPath := filepath.Join(myAbsPath, "..")
-	fmt.Printf("New Path:\t%s\n", wonkyPath)
+	fmt.Fprintf(w, "New Path:\t%s\n", wonkyPath)
 	// Clean() returns the shorted path equivalent
 	// for example, if my path is /foo/bar/../bar/..
 	// the path will be /foo
 	dirtyPath := myAbsPath + "/.."
-	fmt.Printf("Dirty Path:\t%s\n", dirtyPath)
+	fmt.Fprintf(w, "Dirty Path:\t%s\n", dirtyPath)
 	cleanedPath := filepath.Clean(dirtyPath)
-	fmt.Printf("Cleaned Path:\t%s\n", cleanedPath)
+	fmt.Fprintf(w, "Cleaned Path:\t%s\n", cleanedPath)
 
 	relativePath, err := filepath.Rel(cleanedPath, myAbsPath)
 	if err != nil {
-		fmt.Errorf("Could not get relative path")
+		return lessonError("Filepath", ErrFilesystem, "getting relative path", err)
 	}
-	fmt.Printf("Relative path of cleaned path to this dir:\t%s\n", relativePath)
+	fmt.Fprintf(w, "Relative path of cleaned path to this dir:\t%s\n", relativePath)
 
 	fileToGet := "go.mod"
 	ext := filepath.Ext(fileToGet)
-	fmt.Printf("Extension of file %s is %s\n", fileToGet, ext)
+	fmt.Fprintf(w, "Extension of file %s is %s\n", fileToGet, ext)
 
 	// Walk() lets us "walk" a file tree rooted at root
 	// we pass it a walkFn for each file or dir in the tree
-	tmpDir, err := prepaterTestDirTree("dir/to/walk/skip")
+	tmpDir, err := prepaterTestDirTree(w, "dir/to/walk/skip")
 	if err != nil {
-		fmt.Printf("Unable to create test dir tree: %v\n", err)
-		return ""
+		return lessonError("Filepath", ErrFilesystem, "creating test dir tree", err)
 	}
 
 	defer os.RemoveAll(tmpDir)
-	os.Chdir(tmpDir)
+
+	// we could os.Chdir(tmpDir) and walk ".", but the working dir belongs
+	// to the whole process, so we walk tmpDir and print paths relative to it
 
 	subDirToSkip := "skip"
 
@@ -1837,53 +1979,56 @@ func Filepath() string {
 	// Walk() will pass the path string
 	walkFn := func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			fmt.Printf("Prevent panic by handling failure accessing a path %q: %v\n", path, err)
+			fmt.Fprintf(w, "Prevent panic by handling failure accessing a path %q: %v\n", path, err)
 			return err
 		}
 
 		// if it is a directory and the name is
 		// the skip directory, we skip
 		if info.IsDir() && info.Name() == subDirToSkip {
-			fmt.Printf("Skipping a dir without errors: %+v\n", info.Name())
+			fmt.Fprintf(w, "Skipping a dir without errors: %+v\n", info.Name())
 			return filepath.SkipDir
 		}
 
-		fmt.Printf("Visited file or dir: %q\n", path)
+		rel, err := filepath.Rel(tmpDir, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Visited file or dir: %q\n", rel)
 		return nil
 	}
 
-	fmt.Printf("On Unix:\n")
-	err = filepath.Walk(".", walkFn)
+	fmt.Fprintf(w, "On Unix:\n")
+	err = filepath.Walk(tmpDir, walkFn)
 	if err != nil {
-		fmt.Printf("Error walking the path %q: %v\n", tmpDir, err)
-		return ""
+		return lessonError("Filepath", ErrFilesystem, "walking test dir tree", err)
 	}
 
-	return "Filepath"
+	return nil
 }
 
-func pwd() string {
-	pwd := os.Getenv("PWD")
+func pwd(w io.Writer, proc ProcessInfo) string {
+	pwd := proc.Getenv("PWD")
 	if pwd == "" {
-		fmt.Printf("Unable to get pwd\n")
+		fmt.Fprintf(w, "Unable to get pwd\n")
 		return ""
 	}
-	fmt.Printf("%s = %s\n", "PWD", pwd)
+	fmt.Fprintf(w, "%s = %s\n", "PWD", pwd)
 
 	fileinfo, err := os.Lstat(pwd) // return fileinfo struct of dir
 	if err != nil {
-		fmt.Printf("Could not get dir info: %s\n", err)
+		fmt.Fprintf(w, "Could not get dir info: %s\n", err)
 		return ""
 	}
 
 	if fileinfo.Mode()&os.ModeSymlink != 0 { // AND bitmasks
 		realpath, err := filepath.EvalSymlinks(pwd)
 		if err != nil {
-			fmt.Printf("Error getting real path: %v\n", err)
+			fmt.Fprintf(w, "Error getting real path: %v\n", err)
 			return ""
 		}
 
-		fmt.Printf("PWD: %s\n", realpath)
+		fmt.Fprintf(w, "PWD: %s\n", realpath)
 		return realpath
 
 	}
@@ -1899,51 +2044,68 @@ func ensureBaseDir(fpath string) error {
 	return os.MkdirAll(baseDir, 0755)
 }
 
-func permissions() {
+func permissions(w io.Writer, env *Env) error {
 	//pwd := pwd()
-	err := ensureBaseDir("./tmp")
+	// every run gets its own scratch dir rather than sharing ./tmp
+	// with whatever else is running in the same working dir
+	scratch, err := ioutil.TempDir("", "golearn-os")
 	if err != nil {
-		log.Fatal(err)
+		return lessonError("OS", ErrFilesystem, "creating scratch dir", err)
 	}
+	defer os.RemoveAll(scratch)
+	dummyFile := filepath.Join(scratch, "tmp", "dummyFile.txt")
 
-	emptyFile, err := os.Create("./tmp/dummyFile.txt")
+	// ensureBaseDir makes the directory a file lives in,
+	// so hand it the file path rather than the directory
+	err = ensureBaseDir(dummyFile)
 	if err != nil {
-		log.Fatal(err)
+		return lessonError("OS", ErrFilesystem, "creating tmp dir", err)
+	}
+
+	emptyFile, err := os.Create(dummyFile)
+	if err != nil {
+		return lessonError("OS", ErrFilesystem, "creating tmp/dummyFile.txt", err)
 	}
 	defer emptyFile.Close()
 
-	log.Printf("%v\n", emptyFile)
+	env.logf(w, "%T at %s", emptyFile, env.addr(emptyFile))
+	return nil
 }
 
 // OS covers what is inside th OS Go packages
-func OS() string {
-	fmt.Println("\nShowing os Basics in Go...")
+func OS(w io.Writer, env *Env) error {
+	w = output(w)
+	fmt.Fprintln(w, "\nShowing os Basics in Go...")
 
 	// os.Chdir(dir) - cd dir
 	// os.Chmod(name, mode) - cmod mode file
 
+	// most of what we print comes from os.Getpid(), os.Environ() and friends,
+	// env hands us the same values but lets deterministic runs fake them
+	// os.Executable returns our executable location, and the error we see
+	// here is the one it gave back
+	proc, err := env.process()
+	if err != nil {
+		return lessonError("OS", ErrProcess, "getting executable", err)
+	}
+
 	// os.Environ() prints all environment variables
-	env := os.Environ()
-	fmt.Printf("Checking if you have Chapel installed...\n")
-	for _, e := range env {
+	fmt.Fprintf(w, "Checking if you have Chapel installed...\n")
+	for _, e := range proc.Environ {
 		if strings.Contains(e, "CHPL_HOME") {
-			fmt.Printf("YES!\t%s\n", e)
+			fmt.Fprintf(w, "YES!\t%s\n", e)
 			break
 		}
 	}
 
 	// Executable returns our executable location
-	exe, err := os.Executable()
-	if err != nil {
-		fmt.Errorf("Error getting executable: %v", err)
-	}
-	fmt.Printf("Executable path: %s\n", exe)
+	fmt.Fprintf(w, "Executable path: %s\n", proc.Executable)
 
 	// os.Exit will forcibly terminate the program
 	// if you uncomment what is below, the deferred
 	// print will not be completed, as the process
 	// will just completely exit
-	//defer fmt.Printf("I WONT BE PRINTED")
+	//defer fmt.Fprintf(w, "I WONT BE PRINTED")
 	//os.Exit(1)
 
 	// os.Expand performs mapping to strings similar to
@@ -1960,30 +2122,29 @@ func OS() string {
 		return ""
 	}
 
-	fmt.Printf("Expanding: Good ${DAY_PART}, $NAME! to...\n")
-	fmt.Printf("%v\n", os.Expand("Good ${DAY_PART}, $NAME!", mapper))
+	fmt.Fprintf(w, "Expanding: Good ${DAY_PART}, $NAME! to...\n")
+	fmt.Fprintf(w, "%v\n", os.Expand("Good ${DAY_PART}, $NAME!", mapper))
 
 	// os env are basically stored in a map
 	// we use a key to os.Getenv and get
 	// the value back out
-	chapelPath := os.Getenv("CHPL_HOME")
-	fmt.Printf("%s = %s\n", "CHPL_HOME", chapelPath)
+	chapelPath := proc.Getenv("CHPL_HOME")
+	fmt.Fprintf(w, "%s = %s\n", "CHPL_HOME", chapelPath)
 
 	// Getpagesize could be useful to get some
-	pageSize := os.Getpagesize()
-	fmt.Printf("Page Size: %d bytes\n", pageSize)
+	pageSize := proc.PageSize // os.Getpagesize()
+	fmt.Fprintf(w, "Page Size: %d bytes\n", pageSize)
 
-	pid := os.Getpid()   // process id of caller
-	ppid := os.Getppid() // process id of callers parent
-	uid := os.Getuid()   // user id of caller
-	fmt.Printf("pid\t\t%d\nppid\t\t%d\nuid\t\t%d\n", pid, ppid, uid)
+	pid := proc.Pid   // os.Getpid(), process id of caller
+	ppid := proc.Ppid // os.Getppid(), process id of callers parent
+	uid := proc.Uid   // os.Getuid(), user id of caller
+	fmt.Fprintf(w, "pid\t\t%d\nppid\t\t%d\nuid\t\t%d\n", pid, ppid, uid)
 
-	hostname, err := os.Hostname()
+	hostname, err := env.hostname() // os.Hostname()
 	if err != nil {
-		fmt.Errorf("Error getting Hostname: %v", err)
-		return ""
+		return lessonError("OS", ErrProcess, "getting hostname", err)
 	}
-	fmt.Printf("hostname\t%s\n", hostname)
+	fmt.Fprintf(w, "hostname\t%s\n", hostname)
 
 	/* FILEMODES
 	   // The single letters are the abbreviations
@@ -2008,8 +2169,6 @@ func OS() string {
 	   ModePerm FileMode = 0777 // Unix permission bits
 	*/
 
-	pwd()
-	permissions()
-
-	return "OS"
+	pwd(w, proc)
+	return permissions(w, env)
 }
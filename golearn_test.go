@@ -1,9 +1,53 @@
 package golearn
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
+// robots serves the robots.txt DeferPanicRecover fetches,
+// so the suite never needs the internet
+var robots *httptest.Server
+
+func TestMain(m *testing.M) {
+	robots = httptest.NewServer(RobotsHandler())
+	code := m.Run()
+	robots.Close()
+	os.Exit(code)
+}
+
+// useRobots points env at srv's robots.txt
+func useRobots(env *Env, srv *httptest.Server) {
+	env.UseRobots(srv.URL+"/robots.txt", srv.Client())
+}
+
+// systemEnv is the real machine, except DeferPanicRecover
+// fetches its robots.txt from the suite's local server
+func systemEnv() *Env {
+	env := SystemEnv()
+	useRobots(env, robots)
+	return env
+}
+
+// deterministicEnv is DeterministicEnv with the suite's local robots.txt
+func deterministicEnv() *Env {
+	env := DeterministicEnv()
+	useRobots(env, robots)
+	return env
+}
+
 func TestHello(t *testing.T) {
 	expected := "Hello World!"
 	if ret := Hello(); ret != expected {
@@ -11,111 +55,324 @@ func TestHello(t *testing.T) {
 	}
 }
 
-func TestDeclarations(t *testing.T) {
-	expected := "Declarations"
-	if ret := Declarations(); ret != expected {
-		t.Errorf("Declarations() = %q, want %q", ret, expected)
+func TestLessons(t *testing.T) {
+	lessons := Lessons()
+	if len(lessons) == 0 {
+		t.Fatal("no lessons registered")
+	}
+	for _, l := range lessons {
+		l := l
+		t.Run(l.Name(), func(t *testing.T) {
+			var out bytes.Buffer
+			if err := l.Run(context.Background(), &out, systemEnv()); err != nil {
+				t.Errorf("%s.Run() error = %v", l.Name(), err)
+			}
+			if out.Len() == 0 {
+				t.Errorf("%s.Run() wrote nothing to its writer", l.Name())
+			}
+		})
 	}
 }
 
-func TestConversions(t *testing.T) {
-	expected := "Conversions"
-	if ret := Conversions(); ret != expected {
-		t.Errorf("Conversions() = %q, want %q", ret, expected)
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"Loops", "loops", "LOOPS"} {
+		l, ok := Lookup(name)
+		if !ok {
+			t.Fatalf("Lookup(%q) found nothing", name)
+		}
+		if l.Name() != "Loops" {
+			t.Errorf("Lookup(%q) = %q, want %q", name, l.Name(), "Loops")
+		}
 	}
-}
-func TestPrimitives(t *testing.T) {
-	expected := "Primitives"
-	if ret := Primitives(); ret != expected {
-		t.Errorf("Primitives() = %q, want %q", ret, expected)
+
+	if _, ok := Lookup("NotALesson"); ok {
+		t.Error("Lookup(\"NotALesson\") found a lesson")
 	}
 }
 
-func TestConstants(t *testing.T) {
-	expected := "Constants"
-	if ret := Constants(); ret != expected {
-		t.Errorf("Constants() = %q, want %q", ret, expected)
+func TestLessonsWithTag(t *testing.T) {
+	var names []string
+	for _, l := range LessonsWithTag("concurrency") {
+		names = append(names, l.Name())
+	}
+	expected := []string{"GoRoutines", "Channels"}
+	if len(names) != len(expected) {
+		t.Fatalf("LessonsWithTag(\"concurrency\") = %v, want %v", names, expected)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("LessonsWithTag(\"concurrency\") = %v, want %v", names, expected)
+		}
 	}
 }
 
-func TestArraysAndSlices(t *testing.T) {
-	expected := "ArraysAndSlices"
-	if ret := ArraysAndSlices(); ret != expected {
-		t.Errorf("ArraysAndSlices() = %q, want %q", ret, expected)
-	}
+func TestRegisterDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register of a duplicate name did not panic")
+		}
+	}()
+	Register(NewLesson("loops", "a second loops lesson", nil, plain(Loops)))
 }
-func TestMapsAndStructs(t *testing.T) {
-	expected := "MapsAndStructs"
-	if ret := MapsAndStructs(); ret != expected {
-		t.Errorf("MapsAndStructs() = %q, want %q", ret, expected)
+
+// every lesson should print through the writer it is handed,
+// so swap stdout for a pipe and make sure nothing leaks onto it
+func TestLessonsOnlyUseWriter(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
 	}
-}
+	stdout := os.Stdout
+	os.Stdout = w
+	leaked := make(chan []byte)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		leaked <- b
+	}()
 
-func TestControlFlow(t *testing.T) {
-	expected := "ControlFlow"
-	if ret := ControlFlow(); ret != expected {
-		t.Errorf("ControlFlow() = %q, want %q", ret, expected)
+	for _, l := range Lessons() {
+		l.Run(context.Background(), ioutil.Discard, systemEnv())
+	}
+
+	os.Stdout = stdout
+	w.Close()
+	if b := <-leaked; len(b) != 0 {
+		t.Errorf("lessons wrote %d bytes straight to stdout:\n%s", len(b), b)
 	}
 }
 
-func TestLoops(t *testing.T) {
-	expected := "Loops"
-	if ret := Loops(); ret != expected {
-		t.Errorf("Loops() = %q, want %q", ret, expected)
+func TestDemoWritersUseOut(t *testing.T) {
+	var out bytes.Buffer
+	writers := []Writer{ConsoleWriter{Out: &out}}
+	for _, w := range writers {
+		out.Reset()
+		if _, err := w.Write([]byte("hello")); err != nil {
+			t.Fatalf("%T.Write() error = %v", w, err)
+		}
+		if !bytes.Contains(out.Bytes(), []byte("hello")) {
+			t.Errorf("%T.Write() wrote %q, want it to contain %q", w, out.String(), "hello")
+		}
+	}
+
+	out.Reset()
+	bwc := NewBufferedWriterCloser(ConsoleWriter{Out: &out}, 8)
+	bwc.Write([]byte("What is up boys"))
+	bwc.Close()
+	expected := "What is \nup boys\n"
+	if out.String() != expected {
+		t.Errorf("BufferedWriterCloser wrote %q, want %q", out.String(), expected)
 	}
 }
-func TestDeferPanicRecover(t *testing.T) {
-	expected := "DeferPanicRecover"
-	if ret := DeferPanicRecover(); ret != expected {
-		t.Errorf("DeferPanicRecover() = %q, want %q", ret, expected)
+
+func TestDeterministicEnv(t *testing.T) {
+	env := DeterministicEnv()
+	a, b := new(int), new(int)
+	first := env.addr(a)
+	if env.addr(b) == first {
+		t.Errorf("two pointers both formatted as %s", first)
+	}
+	if env.addr(a) != first {
+		t.Errorf("the same pointer formatted as %s then %s", first, env.addr(a))
+	}
+	if other := DeterministicEnv().addr(b); other != first {
+		t.Errorf("a fresh DeterministicEnv formatted its first pointer as %s, want %s", other, first)
+	}
+
+	proc, err := env.process()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if home := proc.Getenv("HOME"); home != "/home/gopher" {
+		t.Errorf("Getenv(\"HOME\") = %q, want %q", home, "/home/gopher")
+	}
+
+	// lessons that consult their env print the same bytes every run
+	for _, name := range []string{"DeferPanicRecover", "Pointers", "Interfaces", "Filepath", "OS"} {
+		l, _ := Lookup(name)
+		var first, second bytes.Buffer
+		l.Run(context.Background(), &first, deterministicEnv())
+		l.Run(context.Background(), &second, deterministicEnv())
+		if first.String() != second.String() {
+			t.Errorf("%s printed different output for two deterministic runs:\n%s", name, diffLines(first.String(), second.String()))
+		}
 	}
 }
 
-func TestPointers(t *testing.T) {
-	expected := "Pointers"
-	if ret := Pointers(); ret != expected {
-		t.Errorf("Pointers() = %q, want %q", ret, expected)
+func TestNilEnvIsSystem(t *testing.T) {
+	var env *Env
+	host, _ := os.Hostname()
+	if got, _ := env.hostname(); got != host {
+		t.Errorf("nil Env hostname = %q, want %q", got, host)
+	}
+	if got := env.addr(env); got != "0x0" {
+		t.Errorf("nil Env addr(nil) = %q, want %q", got, "0x0")
 	}
 }
 
-func TestFunctions(t *testing.T) {
-	expected := "Functions"
-	if ret := Functions(); ret != expected {
-		t.Errorf("Functions() = %q, want %q", ret, expected)
+func TestDeferPanicRecoverFetchesRobots(t *testing.T) {
+	var out bytes.Buffer
+	DeferPanicRecover(context.Background(), &out, systemEnv())
+	if !strings.Contains(out.String(), SampleRobotsTxt) {
+		t.Errorf("DeferPanicRecover did not print the robots.txt it was served, got:\n%s", out.String())
 	}
 }
 
-func TestInterfaces(t *testing.T) {
-	expected := "Interfaces"
-	if ret := Interfaces(); ret != expected {
-		t.Errorf("Interfaces() = %q, want %q", ret, expected)
+type failingFetcher struct{ err error }
+
+func (f failingFetcher) Do(req *http.Request) (*http.Response, error) { return nil, f.err }
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestLessonErrors(t *testing.T) {
+	offline := errors.New("no route to host")
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	tests := []struct {
+		name   string
+		lesson string
+		w      io.Writer
+		env    func() *Env
+		kind   error
+		cause  error
+	}{
+		{"fetch fails", "DeferPanicRecover", ioutil.Discard, func() *Env {
+			env := deterministicEnv()
+			env.Fetcher = failingFetcher{offline}
+			return env
+		}, ErrFetch, offline},
+		{"robots.txt missing", "DeferPanicRecover", ioutil.Discard, func() *Env {
+			env := deterministicEnv()
+			useRobots(env, notFound)
+			return env
+		}, ErrFetch, nil},
+		{"no working dir", "Filepath", ioutil.Discard, func() *Env {
+			env := deterministicEnv()
+			env.Getwd = func() (string, error) { return "", os.ErrNotExist }
+			return env
+		}, ErrFilesystem, os.ErrNotExist},
+		{"no hostname", "OS", ioutil.Discard, func() *Env {
+			env := deterministicEnv()
+			env.Hostname = func() (string, error) { return "", os.ErrPermission }
+			return env
+		}, ErrProcess, os.ErrPermission},
+		{"output fails", "Interfaces", failingWriter{}, deterministicEnv, ErrOutput, nil},
+	}
+	for _, tt := range tests {
+		l, _ := Lookup(tt.lesson)
+		err := l.Run(context.Background(), tt.w, tt.env())
+		if !errors.Is(err, tt.kind) {
+			t.Errorf("%s: %s.Run() error = %v, want a %v", tt.name, tt.lesson, err, tt.kind)
+			continue
+		}
+		if tt.cause != nil && !errors.Is(err, tt.cause) {
+			t.Errorf("%s: %s.Run() error = %v, want it to wrap %v", tt.name, tt.lesson, err, tt.cause)
+		}
+		var lerr *LessonError
+		if !errors.As(err, &lerr) || lerr.Lesson != tt.lesson {
+			t.Errorf("%s: %s.Run() error = %#v, want a *LessonError from %s", tt.name, tt.lesson, err, tt.lesson)
+		}
 	}
 }
 
-func TestGoRoutines(t *testing.T) {
-	expected := "GoRoutines"
-	if ret := GoRoutines(); ret != expected {
-		t.Errorf("GoRoutines() = %q, want %q", ret, expected)
+func TestRunRecoversPanics(t *testing.T) {
+	l := NewLesson("Panics", "a lesson that blows up", nil, func(ctx context.Context, w io.Writer, env *Env) error {
+		panic("boom")
+	})
+	err := l.Run(context.Background(), ioutil.Discard, nil)
+	if !errors.Is(err, ErrPanic) {
+		t.Fatalf("Run() error = %v, want a %v", err, ErrPanic)
+	}
+	if !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Run() error = %q, want it to mention the panic value", err)
 	}
 }
 
-func TestChannels(t *testing.T) {
-	expected := "Channels"
-	if ret := Channels(); ret != expected {
-		t.Errorf("Channels() = %q, want %q", ret, expected)
+// every run of a lesson gets its own state and puts process wide settings
+// back, so running all of them at once, twice over, prints exactly
+// what running them one at a time does (go test -race checks the rest)
+func TestLessonsInParallel(t *testing.T) {
+	maxProcs := runtime.GOMAXPROCS(-1)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lessons := Lessons()
+	outs := make([]bytes.Buffer, 2*len(lessons))
+	errs := make([]error, len(outs))
+	var wg sync.WaitGroup
+	for i := range outs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = lessons[i%len(lessons)].Run(context.Background(), &outs[i], deterministicEnv())
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range outs {
+		l := lessons[i%len(lessons)]
+		if errs[i] != nil {
+			t.Errorf("%s.Run() error = %v", l.Name(), errs[i])
+			continue
+		}
+		checkGolden(t, l.Name(), outs[i].String())
+	}
+
+	if got := runtime.GOMAXPROCS(-1); got != maxProcs {
+		t.Errorf("GOMAXPROCS = %d after the lessons ran, want %d", got, maxProcs)
+	}
+	if got, _ := os.Getwd(); got != wd {
+		t.Errorf("working dir = %s after the lessons ran, want %s", got, wd)
 	}
 }
 
-func TestFilepath(t *testing.T) {
-	expected := "Filepath"
-	if ret := Filepath(); ret != expected {
-		t.Errorf("Filepath() = %q, want %q", ret, expected)
+func TestRunWithTimeout(t *testing.T) {
+	checkLeaks(t)
+	release := make(chan struct{})
+	defer close(release)
+	stuck := NewLesson("Stuck", "a lesson that ignores its context", nil, func(ctx context.Context, w io.Writer, env *Env) error {
+		fmt.Fprintln(w, "before")
+		<-release
+		fmt.Fprintln(w, "after")
+		return nil
+	})
+
+	var out bytes.Buffer
+	err := RunWithTimeout(context.Background(), stuck, &out, nil, 20*time.Millisecond)
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RunWithTimeout() error = %v, want a %v", err, ErrTimeout)
+	}
+	var terr *TimeoutError
+	if !errors.As(err, &terr) || terr.Lesson != "Stuck" || terr.Err != nil {
+		t.Fatalf("RunWithTimeout() error = %#v, want a *TimeoutError from a lesson that never returned", err)
+	}
+	if len(terr.Goroutines) == 0 || !strings.Contains(strings.Join(terr.Goroutines, "\n"), "TestRunWithTimeout") {
+		t.Errorf("TimeoutError.Goroutines = %q, want the stuck lesson's stack", terr.Goroutines)
+	}
+
+	// the lesson may carry on printing, but not into our buffer
+	release <- struct{}{}
+	if out.String() != "before\n" {
+		t.Errorf("RunWithTimeout() wrote %q, want %q", out.String(), "before\n")
+	}
+
+	l, _ := Lookup("Channels")
+	if err := RunWithTimeout(context.Background(), l, ioutil.Discard, nil, time.Minute); err != nil {
+		t.Errorf("RunWithTimeout(Channels) error = %v", err)
 	}
 }
 
-func TestOS(t *testing.T) {
-	expected := "OS"
-	if ret := OS(); ret != expected {
-		t.Errorf("OS() = %q, want %q", ret, expected)
+func TestWaitCanceled(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	defer wg.Done()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := wait(ctx, &wg); err != context.Canceled {
+		t.Errorf("wait() error = %v, want %v", err, context.Canceled)
 	}
 }
@@ -0,0 +1,100 @@
+package golearn
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// checkLeaks fails t if goroutines started after it is called are still
+// running once the test (and its subtests) are over, call it first thing
+// in a lesson test:
+//
+//	func TestMyLesson(t *testing.T) {
+//		checkLeaks(t)
+//		...
+//	}
+//
+// goroutines are process wide, so don't use it in tests running in parallel
+func checkLeaks(t *testing.T) {
+	t.Helper()
+	before := goroutines()
+	t.Cleanup(func() {
+		if leaked := leakedGoroutines(before, leakGrace); len(leaked) != 0 {
+			t.Errorf("%d goroutines leaked:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+		}
+	})
+}
+
+// every lesson cleans up after itself
+func TestLessonsDoNotLeak(t *testing.T) {
+	for _, l := range Lessons() {
+		l := l
+		t.Run(l.Name(), func(t *testing.T) {
+			checkLeaks(t)
+			if err := l.Run(context.Background(), ioutil.Discard, deterministicEnv()); err != nil {
+				t.Errorf("%s.Run() error = %v", l.Name(), err)
+			}
+		})
+	}
+}
+
+func TestLoggerStops(t *testing.T) {
+	checkLeaks(t)
+	var out bytes.Buffer
+	log := NewLogger(&out, LoggerConfig{Now: deterministicEnv().now})
+	log.Info("App is starting")
+
+	closed := make(chan error, 1)
+	go func() { closed <- log.Close() }()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("logger kept running after Close")
+	}
+	if expected := "2009-11-10T23:00:00 - [INFO]App is starting\n"; out.String() != expected {
+		t.Errorf("logger wrote %q, want %q", out.String(), expected)
+	}
+}
+
+func TestRunWithTimeoutFindsLeaks(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	leaky := NewLesson("Leaky", "a lesson that forgets a goroutine", nil, func(ctx context.Context, w io.Writer, env *Env) error {
+		go func() { <-release }()
+		return nil
+	})
+
+	err := RunWithTimeout(context.Background(), leaky, ioutil.Discard, nil, time.Minute)
+	var lerr *LeakError
+	if !errors.Is(err, ErrLeak) || !errors.As(err, &lerr) {
+		t.Fatalf("RunWithTimeout() error = %v, want a *LeakError", err)
+	}
+	if len(lerr.Goroutines) != 1 || !strings.Contains(lerr.Goroutines[0], "TestRunWithTimeoutFindsLeaks") {
+		t.Errorf("LeakError.Goroutines = %q, want the forgotten goroutine", lerr.Goroutines)
+	}
+}
+
+func TestRunsUserCode(t *testing.T) {
+	tests := []struct {
+		stack string
+		user  bool
+	}{
+		{"goroutine 7 [IO wait]:\ninternal/poll.runtime_pollWait(0x7f)\n\t/go/src/runtime/netpoll.go:351 +0x85\ncreated by net/http.(*Transport).dialConn in goroutine 6\n\t/go/src/net/http/transport.go:1944 +0x174c", false},
+		{"goroutine 9 [chan receive]:\ngithub.com/aljo242/golearn.Channels.func1()\n\t/src/golearn.go:1765 +0x2a\ncreated by github.com/aljo242/golearn.Channels in goroutine 8", true},
+		{"goroutine 3 [select (no cases)]:\nmain.init.func2.1()\n\t/src/main_test.go:20 +0x1a", true},
+	}
+	for _, tt := range tests {
+		if got := runsUserCode(tt.stack); got != tt.user {
+			t.Errorf("runsUserCode(%q) = %v, want %v", strings.SplitN(tt.stack, "\n", 3)[1], got, tt.user)
+		}
+	}
+}
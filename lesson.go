@@ -0,0 +1,203 @@
+package golearn
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Lesson is a single runnable section of the tutorial
+// every lesson in this package registers itself with Register
+// so tools can list, look up and run lessons by name
+type Lesson interface {
+	// Name is the identifier a lesson is looked up by, e.g. "Loops"
+	Name() string
+	// Title is a one line human readable summary of the lesson
+	Title() string
+	// Tags group related lessons together, e.g. "concurrency"
+	Tags() []string
+	// Run executes the lesson, writing everything it prints to w
+	// (os.Stdout when w is nil) and asking env about the machine
+	// (the real one when env is nil)
+	// lessons that block give up once ctx is done, see RunWithTimeout
+	// a lesson that fails returns a *LessonError saying why
+	Run(ctx context.Context, w io.Writer, env *Env) error
+}
+
+// RunFunc is the function behind a Lesson, see Lesson.Run
+type RunFunc func(ctx context.Context, w io.Writer, env *Env) error
+
+// lesson is the Lesson implementation used by NewLesson
+type lesson struct {
+	name  string
+	title string
+	tags  []string
+	run   RunFunc
+}
+
+func (l *lesson) Name() string  { return l.name }
+func (l *lesson) Title() string { return l.title }
+
+func (l *lesson) Tags() []string {
+	tags := make([]string, len(l.tags))
+	copy(tags, l.tags)
+	return tags
+}
+
+// Run turns a panic escaping the lesson into an ErrPanic LessonError,
+// so one broken lesson can't take the rest of a run down with it
+func (l *lesson) Run(ctx context.Context, w io.Writer, env *Env) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = lessonError(l.name, ErrPanic, "running", fmt.Errorf("%v", r))
+		}
+	}()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return l.run(ctx, output(w), env)
+}
+
+// NewLesson wraps a lesson function up as a Lesson
+func NewLesson(name, title string, tags []string, run RunFunc) Lesson {
+	return &lesson{name: name, title: title, tags: tags, run: run}
+}
+
+// plain adapts a lesson that only needs somewhere to print
+func plain(run func(io.Writer) error) RunFunc {
+	return func(_ context.Context, w io.Writer, _ *Env) error {
+		return run(w)
+	}
+}
+
+// withContext adapts a lesson that waits on goroutines but never looks at its Env
+func withContext(run func(context.Context, io.Writer) error) RunFunc {
+	return func(ctx context.Context, w io.Writer, _ *Env) error {
+		return run(ctx, w)
+	}
+}
+
+// withEnv adapts a lesson that consults its Env but never blocks
+func withEnv(run func(io.Writer, *Env) error) RunFunc {
+	return func(_ context.Context, w io.Writer, env *Env) error {
+		return run(w, env)
+	}
+}
+
+// wait is wg.Wait that gives up once ctx is done,
+// the goroutines it was waiting on are left running
+func wait(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// output returns the writer lesson output should go to,
+// which is os.Stdout unless the caller asked for something else
+func output(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
+	}
+	return w
+}
+
+// lockedWriter serialises writes from many goroutines onto one writer,
+// for lessons that print from several goroutines at once
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.w.Write(p)
+}
+
+// the registry keeps lessons in the order they were registered,
+// which is also the order the tutorial is meant to be read in
+var registry = struct {
+	sync.RWMutex
+	lessons []Lesson
+	byName  map[string]Lesson
+}{byName: make(map[string]Lesson)}
+
+// Register adds a lesson to the package registry
+// like http.Handle or sql.Register, it panics if the lesson is nil
+// or a lesson with the same name (ignoring case) is already registered
+func Register(l Lesson) {
+	if l == nil {
+		panic("golearn: Register lesson is nil")
+	}
+	key := strings.ToLower(l.Name())
+	if key == "" {
+		panic("golearn: Register lesson has no name")
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	if _, dup := registry.byName[key]; dup {
+		panic(fmt.Sprintf("golearn: Register called twice for lesson %q", l.Name()))
+	}
+	registry.byName[key] = l
+	registry.lessons = append(registry.lessons, l)
+}
+
+// Lessons returns every registered lesson in registration order
+func Lessons() []Lesson {
+	registry.RLock()
+	defer registry.RUnlock()
+	lessons := make([]Lesson, len(registry.lessons))
+	copy(lessons, registry.lessons)
+	return lessons
+}
+
+// Lookup finds a registered lesson by name, ignoring case
+func Lookup(name string) (Lesson, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	l, ok := registry.byName[strings.ToLower(name)]
+	return l, ok
+}
+
+// LessonsWithTag returns the registered lessons carrying tag, in registration order
+func LessonsWithTag(tag string) []Lesson {
+	var tagged []Lesson
+	for _, l := range Lessons() {
+		for _, t := range l.Tags() {
+			if strings.EqualFold(t, tag) {
+				tagged = append(tagged, l)
+				break
+			}
+		}
+	}
+	return tagged
+}
+
+// Tags returns every tag used by a registered lesson, sorted
+func Tags() []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, l := range Lessons() {
+		for _, t := range l.Tags() {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -0,0 +1,263 @@
+package golearn
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level is how serious a log entry is
+type Level int
+
+// the levels, least serious first
+const (
+	LevelInfo Level = iota
+	LevelWarning
+	LevelError
+)
+
+var levelNames = [...]string{"INFO", "WARNING", "ERROR"}
+
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// LogEntry is one line of a Logger's output
+type LogEntry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  []Field // extra key/value pairs, in the order they were given
+}
+
+// Field is a key/value pair attached to a log entry, e.g.
+//
+//	log.Error("upload failed", Field{"file", name}, Field{"attempt", 3})
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Sink is somewhere a Logger writes entries: any Writer, e.g. a
+// ConsoleWriter, FileWriter, TCPWriter or plain io.Writer, encoded with
+// Encoder (TextEncoder when nil) and skipping anything below MinLevel
+type Sink struct {
+	W        Writer
+	Encoder  Encoder
+	MinLevel Level
+}
+
+// FullPolicy is what a Logger does with an entry when its buffer is full
+type FullPolicy int
+
+const (
+	// BlockWhenFull makes the caller wait for room, nothing is lost
+	// but a slow writer slows everyone logging down
+	BlockWhenFull FullPolicy = iota
+	// DropWhenFull throws the entry away and carries on
+	DropWhenFull
+	// CountWhenFull throws the entry away too, but the logger then writes
+	// a warning saying how many entries went missing once it catches up
+	CountWhenFull
+)
+
+// the errors Logger.Log returns
+var (
+	// ErrLoggerClosed means the entry was logged after Close
+	ErrLoggerClosed = errors.New("logger closed")
+	// ErrLogDropped means the buffer was full and the entry was thrown away
+	ErrLogDropped = errors.New("log buffer full, entry dropped")
+)
+
+// DefaultLogBuffer is how many entries a Logger holds when LoggerConfig
+// doesn't say, the same as the logCh the Channels lesson used to sketch
+const DefaultLogBuffer = 50
+
+// LoggerConfig tunes a Logger, the zero value is a usable default
+type LoggerConfig struct {
+	// Buffer is how many entries can be waiting to be written,
+	// DefaultLogBuffer when 0
+	Buffer int
+	// OnFull is what happens to an entry logged while the buffer is full
+	OnFull FullPolicy
+	// Now is the clock entries are stamped with, time.Now when nil
+	Now func() time.Time
+	// Sinks are written to as well as the writer given to NewLogger
+	Sinks []Sink
+}
+
+// Logger writes log entries from a goroutine of its own, so logging
+// never waits on w (unless the buffer fills up and OnFull says to)
+// it is safe to log from many goroutines at once
+// every Logger has to be closed, or its goroutine leaks
+type Logger struct {
+	sinks  []Sink
+	now    func() time.Time
+	onFull FullPolicy
+
+	// entries carries the entries to write and done is an empty struct
+	// channel, this is what is known as a signal only channel:
+	// nothing is ever sent on it, closing it is the signal
+	entries chan LogEntry
+	done    chan struct{}
+	stopped chan struct{} // closed once the goroutine has returned
+
+	// mu guards closed, senders hold it for reading so Close
+	// can't slip in between checking closed and sending
+	mu     sync.RWMutex
+	closed bool
+
+	dropped uint64 // every entry dropped, read and written atomically
+	missed  uint64 // dropped entries not yet reported, for CountWhenFull
+	err     error  // first error writing to a sink, only the goroutine touches it
+}
+
+// NewLogger starts a Logger writing every entry to w with the TextEncoder,
+// and to each of cfg.Sinks
+// w may be nil when there are sinks, otherwise nil means os.Stdout
+func NewLogger(w io.Writer, cfg LoggerConfig) *Logger {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = DefaultLogBuffer
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	var sinks []Sink
+	if w != nil || len(cfg.Sinks) == 0 {
+		sinks = append(sinks, Sink{W: output(w)})
+	}
+	sinks = append(sinks, cfg.Sinks...)
+	for i := range sinks {
+		if sinks[i].Encoder == nil {
+			sinks[i].Encoder = TextEncoder{}
+		}
+	}
+	l := &Logger{
+		sinks:   sinks,
+		now:     cfg.Now,
+		onFull:  cfg.OnFull,
+		entries: make(chan LogEntry, cfg.Buffer),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// Info logs msg at LevelInfo
+func (l *Logger) Info(msg string, fields ...Field) { l.Log(LevelInfo, msg, fields...) }
+
+// Warn logs msg at LevelWarning
+func (l *Logger) Warn(msg string, fields ...Field) { l.Log(LevelWarning, msg, fields...) }
+
+// Error logs msg at LevelError
+func (l *Logger) Error(msg string, fields ...Field) { l.Log(LevelError, msg, fields...) }
+
+// Log queues msg and its fields to be written at level
+// it returns ErrLoggerClosed after Close, and ErrLogDropped when
+// the buffer was full and the entry was thrown away
+func (l *Logger) Log(level Level, msg string, fields ...Field) error {
+	entry := LogEntry{Time: l.now(), Level: level, Message: msg}
+	if len(fields) != 0 {
+		// copy them, the caller is free to reuse its slice once we return
+		entry.Fields = append([]Field(nil), fields...)
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		return ErrLoggerClosed
+	}
+	if l.onFull == BlockWhenFull {
+		l.entries <- entry
+		return nil
+	}
+
+	// a select with a default case never blocks,
+	// if no other case is ready right now the default runs
+	select {
+	case l.entries <- entry:
+		return nil
+	default:
+		atomic.AddUint64(&l.dropped, 1)
+		if l.onFull == CountWhenFull {
+			atomic.AddUint64(&l.missed, 1)
+		}
+		return ErrLogDropped
+	}
+}
+
+// Dropped is how many entries have been thrown away because the buffer was full
+func (l *Logger) Dropped() uint64 {
+	return atomic.LoadUint64(&l.dropped)
+}
+
+// Close stops the logger, writing every entry still in the buffer first,
+// and waits for its goroutine to return
+// it returns the first error writing to a sink, if there was one
+// closing a closed Logger does nothing
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		<-l.stopped
+		return l.err
+	}
+	l.closed = true
+	l.mu.Unlock()
+
+	close(l.done)
+	<-l.stopped
+	return l.err
+}
+
+// we have a select{} control block in this function
+// select multiplexes incoming signals, waiting on whichever is ready first
+func (l *Logger) run() {
+	defer close(l.stopped)
+	for {
+		select {
+		case entry := <-l.entries:
+			l.write(entry)
+
+		case <-l.done:
+			// a bare break would only leave the select, not the for loop,
+			// so we drain what is left and return instead
+			for {
+				select {
+				case entry := <-l.entries:
+					l.write(entry)
+				default:
+					l.reportMissed()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (l *Logger) write(entry LogEntry) {
+	for _, s := range l.sinks {
+		if entry.Level < s.MinLevel {
+			continue
+		}
+		if _, err := s.W.Write(s.Encoder.Encode(entry)); err != nil && l.err == nil {
+			l.err = err
+		}
+	}
+	if len(l.entries) == 0 {
+		l.reportMissed() // caught up, so say what we missed
+	}
+}
+
+func (l *Logger) reportMissed() {
+	if n := atomic.SwapUint64(&l.missed, 0); n != 0 {
+		l.write(LogEntry{Time: l.now(), Level: LevelWarning, Message: fmt.Sprintf("logger dropped %d entries, buffer full", n)})
+	}
+}
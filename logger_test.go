@@ -0,0 +1,189 @@
+package golearn
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// blockingWriter holds every write until release is closed,
+// closing writing when the first one arrives
+type blockingWriter struct {
+	once    sync.Once
+	writing chan struct{}
+	release chan struct{}
+	out     lockedWriter
+}
+
+func (b *blockingWriter) Write(p []byte) (int, error) {
+	b.once.Do(func() { close(b.writing) })
+	<-b.release
+	return b.out.Write(p)
+}
+
+func newBlockingWriter() (*blockingWriter, *bytes.Buffer) {
+	var out bytes.Buffer
+	return &blockingWriter{writing: make(chan struct{}), release: make(chan struct{}), out: lockedWriter{w: &out}}, &out
+}
+
+func TestLoggerCloseDrains(t *testing.T) {
+	checkLeaks(t)
+	var out bytes.Buffer
+	log := NewLogger(&out, LoggerConfig{Now: deterministicEnv().now})
+	log.Info("App is starting")
+	log.Warn("disk nearly full")
+	log.Error("disk full")
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	expected := "2009-11-10T23:00:00 - [INFO]App is starting\n" +
+		"2009-11-10T23:00:00 - [WARNING]disk nearly full\n" +
+		"2009-11-10T23:00:00 - [ERROR]disk full\n"
+	if out.String() != expected {
+		t.Errorf("logger wrote %q, want %q", out.String(), expected)
+	}
+	if err := log.Log(LevelInfo, "too late"); err != ErrLoggerClosed {
+		t.Errorf("Log() after Close error = %v, want %v", err, ErrLoggerClosed)
+	}
+	if err := log.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
+
+func TestLoggerFullPolicies(t *testing.T) {
+	tests := []struct {
+		policy  FullPolicy
+		dropped uint64
+		lines   int
+		warning bool
+	}{
+		{BlockWhenFull, 0, 10, false},
+		{DropWhenFull, 7, 3, false},
+		{CountWhenFull, 7, 4, true},
+	}
+	for _, tt := range tests {
+		checkLeaks(t)
+		w, out := newBlockingWriter()
+		log := NewLogger(w, LoggerConfig{Buffer: 2, OnFull: tt.policy})
+
+		// the first entry gets stuck in the writer and two fill the buffer,
+		// the rest either wait or are dropped
+		log.Info("entry 0")
+		<-w.writing
+		var wg sync.WaitGroup
+		var dropErrs int
+		var mu sync.Mutex
+		for i := 1; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if err := log.Log(LevelInfo, fmt.Sprint("entry ", i)); errors.Is(err, ErrLogDropped) {
+					mu.Lock()
+					dropErrs++
+					mu.Unlock()
+				}
+			}(i)
+			if tt.policy != BlockWhenFull {
+				wg.Wait() // one at a time so the count is predictable
+			}
+		}
+		close(w.release)
+		wg.Wait()
+		if err := log.Close(); err != nil {
+			t.Fatalf("policy %d: Close() error = %v", tt.policy, err)
+		}
+
+		if log.Dropped() != tt.dropped || uint64(dropErrs) != tt.dropped {
+			t.Errorf("policy %d: Dropped() = %d with %d ErrLogDropped, want %d", tt.policy, log.Dropped(), dropErrs, tt.dropped)
+		}
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		if len(lines) != tt.lines {
+			t.Errorf("policy %d: wrote %d lines, want %d:\n%s", tt.policy, len(lines), tt.lines, out.String())
+		}
+		if got := strings.Contains(out.String(), "[WARNING]logger dropped 7 entries"); got != tt.warning {
+			t.Errorf("policy %d: reported drops = %v, want %v:\n%s", tt.policy, got, tt.warning, out.String())
+		}
+	}
+}
+
+func TestLoggerWriteError(t *testing.T) {
+	log := NewLogger(failingWriter{}, LoggerConfig{})
+	log.Info("nobody will see this")
+	if err := log.Close(); err == nil {
+		t.Error("Close() error = nil, want the writer's error")
+	}
+}
+
+func TestLevelString(t *testing.T) {
+	for l, expected := range map[Level]string{LevelInfo: "INFO", LevelWarning: "WARNING", LevelError: "ERROR", Level(7): "Level(7)"} {
+		if l.String() != expected {
+			t.Errorf("Level(%d).String() = %q, want %q", int(l), l.String(), expected)
+		}
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	entry := LogEntry{
+		Time:    deterministicTime,
+		Level:   LevelError,
+		Message: "upload failed",
+		Fields:  []Field{{"file", "my notes.txt"}, {"attempt", 3}, {"done", false}},
+	}
+	tests := []struct {
+		enc      Encoder
+		expected string
+	}{
+		{TextEncoder{}, "2009-11-10T23:00:00 - [ERROR]upload failed\tfile=\"my notes.txt\" attempt=3 done=false\n"},
+		{JSONEncoder{}, `{"time":"2009-11-10T23:00:00Z","level":"ERROR","msg":"upload failed","fields":{"file":"my notes.txt","attempt":3,"done":false}}` + "\n"},
+		{LogfmtEncoder{}, "time=2009-11-10T23:00:00Z level=ERROR msg=\"upload failed\" file=\"my notes.txt\" attempt=3 done=false\n"},
+	}
+	for _, tt := range tests {
+		if got := string(tt.enc.Encode(entry)); got != tt.expected {
+			t.Errorf("%T.Encode() = %q, want %q", tt.enc, got, tt.expected)
+		}
+	}
+
+	// every entry stays on one line, whatever is in it
+	odd := LogEntry{Time: deterministicTime, Message: "two\nlines", Fields: []Field{{"ch", make(chan int)}, {"empty", ""}}}
+	for _, enc := range []Encoder{TextEncoder{}, JSONEncoder{}, LogfmtEncoder{}} {
+		if got := string(enc.Encode(odd)); strings.Count(got, "\n") != 1 || !strings.HasSuffix(got, "\n") {
+			t.Errorf("%T.Encode() = %q, want a single line", enc, got)
+		}
+	}
+}
+
+func TestLoggerSinks(t *testing.T) {
+	checkLeaks(t)
+	var console, errs, jsonl bytes.Buffer
+	log := NewLogger(nil, LoggerConfig{
+		Now: deterministicEnv().now,
+		Sinks: []Sink{
+			{W: ConsoleWriter{Out: &console}},
+			{W: &errs, MinLevel: LevelError},
+			{W: &jsonl, Encoder: JSONEncoder{}, MinLevel: LevelWarning},
+		},
+	})
+	log.Info("starting", Field{"port", 8080})
+	log.Warn("slow request", Field{"ms", 1500})
+	log.Error("crashed")
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// ConsoleWriter adds a newline of its own, so count entries not lines
+	if n := strings.Count(console.String(), " - ["); n != 3 {
+		t.Errorf("console sink got %d entries, want 3:\n%s", n, console.String())
+	}
+	if expected := "2009-11-10T23:00:00 - [ERROR]crashed\n"; errs.String() != expected {
+		t.Errorf("error sink got %q, want %q", errs.String(), expected)
+	}
+	expected := `{"time":"2009-11-10T23:00:00Z","level":"WARNING","msg":"slow request","fields":{"ms":1500}}` + "\n" +
+		`{"time":"2009-11-10T23:00:00Z","level":"ERROR","msg":"crashed"}` + "\n"
+	if jsonl.String() != expected {
+		t.Errorf("json sink got %q, want %q", jsonl.String(), expected)
+	}
+}
@@ -0,0 +1,203 @@
+package golearn
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseLevel reads a level the way Level.String writes it,
+// ignoring case and accepting WARN for WARNING
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(s) {
+	case "INFO":
+		return LevelInfo, nil
+	case "WARNING", "WARN":
+		return LevelWarning, nil
+	case "ERROR":
+		return LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
+
+// ParseTextEntry reads back one line written by TextEncoder,
+// with or without its trailing newline
+// the text format only keeps whole seconds, written in UTC, so Time comes
+// back in UTC, and field values all come back as strings
+func ParseTextEntry(line string) (LogEntry, error) {
+	line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+	// 2009-11-10T23:00:00 - [INFO]message<tab>fields
+	const sep = " - ["
+	if len(line) < len(TextTimeFormat)+len(sep) || line[len(TextTimeFormat):len(TextTimeFormat)+len(sep)] != sep {
+		return LogEntry{}, errors.New(`missing "timestamp - [LEVEL]"`)
+	}
+	t, err := time.Parse(TextTimeFormat, line[:len(TextTimeFormat)])
+	if err != nil {
+		return LogEntry{}, err
+	}
+	rest := line[len(TextTimeFormat)+len(sep):]
+	end := strings.IndexByte(rest, ']')
+	if end < 0 {
+		return LogEntry{}, errors.New("missing ] after the level")
+	}
+	level, err := ParseLevel(rest[:end])
+	if err != nil {
+		return LogEntry{}, err
+	}
+	entry := LogEntry{Time: t, Level: level}
+
+	// the encoder quotes any message with a tab in it,
+	// so the first tab is always where the fields start
+	msg := rest[end+1:]
+	if i := strings.IndexByte(msg, '\t'); i >= 0 {
+		if entry.Fields, err = parseLogfmtFields(msg[i+1:]); err != nil {
+			return LogEntry{}, err
+		}
+		msg = msg[:i]
+	}
+	if strings.HasPrefix(msg, `"`) {
+		if msg, err = strconv.Unquote(msg); err != nil {
+			return LogEntry{}, fmt.Errorf("bad quoted message: %w", err)
+		}
+	}
+	entry.Message = msg
+	return entry, nil
+}
+
+// parseLogfmtFields reads the key=value pairs writeLogfmtFields writes
+func parseLogfmtFields(s string) ([]Field, error) {
+	var fields []Field
+	for s != "" {
+		key, rest, err := logfmtToken(s)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(rest, "=") {
+			return nil, fmt.Errorf("field %q has no value", key)
+		}
+		value, rest, err := logfmtToken(rest[1:])
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, Field{Key: key, Value: value})
+		s = strings.TrimPrefix(rest, " ")
+	}
+	return fields, nil
+}
+
+// logfmtToken splits a bare or quoted token off the front of s
+func logfmtToken(s string) (token, rest string, err error) {
+	if !strings.HasPrefix(s, `"`) {
+		end := strings.IndexAny(s, " =")
+		if end < 0 {
+			end = len(s)
+		}
+		return s[:end], s[end:], nil
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++ // skip whatever is escaped
+		case '"':
+			token, err = strconv.Unquote(s[:i+1])
+			return token, s[i+1:], err
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quote in %q", s)
+}
+
+// LogScanner reads TextEncoder lines back one entry at a time, like
+// bufio.Scanner, stopping at the first line it can't parse
+//
+//	sc := NewLogScanner(f)
+//	for sc.Scan() {
+//		entry := sc.Entry()
+//		...
+//	}
+//	if err := sc.Err(); err != nil {
+//		...
+//	}
+type LogScanner struct {
+	sc    *bufio.Scanner
+	line  int
+	entry LogEntry
+	err   error
+}
+
+// NewLogScanner reads entries from r
+func NewLogScanner(r io.Reader) *LogScanner {
+	return &LogScanner{sc: bufio.NewScanner(r)}
+}
+
+// Scan moves on to the next entry, skipping blank lines,
+// and reports whether there was one
+func (s *LogScanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	for s.sc.Scan() {
+		s.line++
+		if strings.TrimSpace(s.sc.Text()) == "" {
+			continue
+		}
+		entry, err := ParseTextEntry(s.sc.Text())
+		if err != nil {
+			s.err = fmt.Errorf("line %d: %w", s.line, err)
+			return false
+		}
+		s.entry = entry
+		return true
+	}
+	s.err = s.sc.Err()
+	return false
+}
+
+// Entry is the entry the last call to Scan read
+func (s *LogScanner) Entry() LogEntry { return s.entry }
+
+// Err is the error that stopped Scan, nil at the end of the input
+func (s *LogScanner) Err() error { return s.err }
+
+// LogQuery picks log entries out, every field left at its zero value
+// matches everything
+type LogQuery struct {
+	Since    time.Time      // entries at or after Since
+	Until    time.Time      // entries before Until
+	MinLevel Level          // entries at least this serious
+	Contains string         // entries whose message contains this
+	Match    *regexp.Regexp // entries whose message matches this
+}
+
+// Matches reports whether e is one of the entries q asks for
+func (q LogQuery) Matches(e LogEntry) bool {
+	switch {
+	case !q.Since.IsZero() && e.Time.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && !e.Time.Before(q.Until):
+		return false
+	case e.Level < q.MinLevel:
+		return false
+	case q.Contains != "" && !strings.Contains(e.Message, q.Contains):
+		return false
+	case q.Match != nil && !q.Match.MatchString(e.Message):
+		return false
+	}
+	return true
+}
+
+// Filter returns the entries q matches, in the order they were given
+func (q LogQuery) Filter(entries []LogEntry) []LogEntry {
+	var matched []LogEntry
+	for _, e := range entries {
+		if q.Matches(e) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
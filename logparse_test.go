@@ -0,0 +1,129 @@
+package golearn
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// the text format keeps seconds in UTC and field values as strings,
+// so entries like these come back exactly as they went in
+var roundTripEntries = []LogEntry{
+	{Time: deterministicTime, Level: LevelInfo, Message: "App is starting"},
+	{Time: deterministicTime.Add(time.Second), Level: LevelWarning, Message: "disk at 91%", Fields: []Field{{"disk", "/dev/sda1"}}},
+	{Time: deterministicTime.Add(time.Minute), Level: LevelError, Message: "upload failed", Fields: []Field{{"file", "my notes.txt"}, {"reason", `said "no"`}, {"empty", ""}, {"eq", "a=b"}}},
+	{Time: deterministicTime.Add(time.Hour), Level: LevelInfo, Message: "two\nlines\tand a tab"},
+	{Time: deterministicTime.Add(2 * time.Hour), Level: LevelInfo, Message: `"quoted" from the start`},
+	{Time: deterministicTime.Add(3 * time.Hour), Level: LevelInfo, Message: "brackets ] and - [dashes]"},
+	{Time: deterministicTime.Add(4 * time.Hour), Level: LevelError, Message: ""},
+}
+
+func TestParseTextEntryRoundTrip(t *testing.T) {
+	for _, want := range roundTripEntries {
+		line := string(TextEncoder{}.Encode(want))
+		got, err := ParseTextEntry(line)
+		if err != nil {
+			t.Errorf("ParseTextEntry(%q) error = %v", line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseTextEntry(%q) = %#v, want %#v", line, got, want)
+		}
+		if again := string(TextEncoder{}.Encode(got)); again != line {
+			t.Errorf("re-encoding %q gave %q", line, again)
+		}
+	}
+}
+
+// a Logger on time.Now logs in the local zone, the text format has to
+// turn that into UTC for the times to parse back to the same instant
+func TestParseTextEntryOtherZone(t *testing.T) {
+	eastern := time.FixedZone("EST", -5*60*60)
+	want := LogEntry{Time: deterministicTime.In(eastern), Level: LevelInfo, Message: "App is starting"}
+	line := string(TextEncoder{}.Encode(want))
+	if expected := "2009-11-10T23:00:00 - [INFO]App is starting\n"; line != expected {
+		t.Errorf("Encode() = %q, want %q", line, expected)
+	}
+	got, err := ParseTextEntry(line)
+	if err != nil {
+		t.Fatalf("ParseTextEntry(%q) error = %v", line, err)
+	}
+	if !got.Time.Equal(want.Time) {
+		t.Errorf("ParseTextEntry(%q).Time = %v, want %v", line, got.Time, want.Time)
+	}
+	q := LogQuery{Since: deterministicTime, Until: deterministicTime.Add(time.Second)}
+	if !q.Matches(got) {
+		t.Errorf("%+v doesn't match %v", q, got.Time)
+	}
+}
+
+func TestParseTextEntryErrors(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"not a log line at all",
+		"2009-11-10 23:00:00 - [INFO]wrong time layout",
+		"2009-11-10T23:00:00 - [INFO",
+		"2009-11-10T23:00:00 - [DEBUG]unknown level",
+		"2009-11-10T23:00:00 - [INFO]\"unterminated",
+		"2009-11-10T23:00:00 - [INFO]fields\tkey",
+		"2009-11-10T23:00:00 - [INFO]fields\tkey=\"unterminated",
+	} {
+		if e, err := ParseTextEntry(line); err == nil {
+			t.Errorf("ParseTextEntry(%q) = %#v, want an error", line, e)
+		}
+	}
+}
+
+func TestLogScanner(t *testing.T) {
+	var log strings.Builder
+	for _, e := range roundTripEntries {
+		log.Write(TextEncoder{}.Encode(e))
+		log.WriteString("\n") // blank lines are skipped
+	}
+	sc := NewLogScanner(strings.NewReader(log.String()))
+	var got []LogEntry
+	for sc.Scan() {
+		got = append(got, sc.Entry())
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, roundTripEntries) {
+		t.Errorf("LogScanner read %d entries, want the %d written", len(got), len(roundTripEntries))
+	}
+
+	sc = NewLogScanner(strings.NewReader(string(TextEncoder{}.Encode(roundTripEntries[0])) + "garbage\n"))
+	for sc.Scan() {
+	}
+	if err := sc.Err(); err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("LogScanner.Err() = %v, want an error about line 2", err)
+	}
+}
+
+func TestLogQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		q        LogQuery
+		expected []int // indexes into roundTripEntries
+	}{
+		{"everything", LogQuery{}, []int{0, 1, 2, 3, 4, 5, 6}},
+		{"since", LogQuery{Since: deterministicTime.Add(time.Hour)}, []int{3, 4, 5, 6}},
+		{"until", LogQuery{Until: deterministicTime.Add(time.Minute)}, []int{0, 1}},
+		{"range", LogQuery{Since: deterministicTime.Add(time.Second), Until: deterministicTime.Add(time.Hour)}, []int{1, 2}},
+		{"at least warning", LogQuery{MinLevel: LevelWarning}, []int{1, 2, 6}},
+		{"contains", LogQuery{Contains: "disk"}, []int{1}},
+		{"regexp", LogQuery{Match: regexp.MustCompile(`^(App|upload) `)}, []int{0, 2}},
+		{"all at once", LogQuery{MinLevel: LevelError, Contains: "upload", Until: deterministicTime.Add(time.Hour)}, []int{2}},
+	}
+	for _, tt := range tests {
+		var expected []LogEntry
+		for _, i := range tt.expected {
+			expected = append(expected, roundTripEntries[i])
+		}
+		if got := tt.q.Filter(roundTripEntries); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: Filter() returned %d entries, want %v", tt.name, len(got), tt.expected)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+// Package matrix is the ArraysAndSlices lesson's identityMatrix grown up:
+// dense float64 matrices of any size
+//
+// the lesson's [3][3]int is an array of arrays, a value, so assigning it
+// or passing it to a function copies all nine ints, and its size is fixed
+// at compile time. A Matrix keeps its elements in one flat slice, row after
+// row, instead: copying a *Matrix copies a pointer, and Row, Col and Slice
+// return views sharing that slice the way b := a[3:6] shares a's array,
+// so writing through a view changes the matrix it came from
+// use Clone for a copy of its own
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrShape means the matrices' sizes don't fit the operation,
+	// e.g. adding a 2x3 to a 3x2
+	ErrShape = errors.New("matrix: sizes don't match")
+	// ErrSingular means a matrix has no inverse, its determinant is 0
+	ErrSingular = errors.New("matrix: singular")
+)
+
+// singular is how close to zero a pivot can get, as a fraction of the
+// matrix's biggest element, before Inverse counts the matrix as singular
+// floats rarely cancel to exactly 0, and it has to be a fraction so that
+// a matrix of tiny numbers isn't singular just for being tiny
+const singular = 1e-12
+
+// Matrix is a rows x cols grid of float64s
+// element (i, j) lives at data[i*stride+j], stride is cols for a matrix
+// of its own and the parent's stride for a view
+type Matrix struct {
+	rows, cols int
+	stride     int
+	data       []float64
+}
+
+// Zeros makes a rows x cols matrix of zeros
+// it panics if either size is negative, the way make does
+func Zeros(rows, cols int) *Matrix {
+	if rows < 0 || cols < 0 {
+		panic(fmt.Sprintf("matrix: negative size %dx%d", rows, cols))
+	}
+	return &Matrix{rows: rows, cols: cols, stride: cols, data: make([]float64, rows*cols)}
+}
+
+// Identity makes the n x n identity matrix, ones down the diagonal
+func Identity(n int) *Matrix {
+	m := Zeros(n, n)
+	for i := 0; i < n; i++ {
+		m.data[i*m.stride+i] = 1
+	}
+	return m
+}
+
+// FromRows makes a matrix by copying rows, which all have to be as long
+func FromRows(rows [][]float64) (*Matrix, error) {
+	if len(rows) == 0 {
+		return Zeros(0, 0), nil
+	}
+	m := Zeros(len(rows), len(rows[0]))
+	for i, row := range rows {
+		if len(row) != m.cols {
+			return nil, fmt.Errorf("%w: row %d has %d columns, row 0 has %d", ErrShape, i, len(row), m.cols)
+		}
+		copy(m.Row(i), row)
+	}
+	return m, nil
+}
+
+// Dims returns the number of rows and columns
+func (m *Matrix) Dims() (rows, cols int) { return m.rows, m.cols }
+
+// At returns element (i, j), it panics if that's outside the matrix
+func (m *Matrix) At(i, j int) float64 {
+	m.check(i, j)
+	return m.data[i*m.stride+j]
+}
+
+// Set sets element (i, j) to v, it panics if that's outside the matrix
+func (m *Matrix) Set(i, j int, v float64) {
+	m.check(i, j)
+	m.data[i*m.stride+j] = v
+}
+
+func (m *Matrix) check(i, j int) {
+	if i < 0 || i >= m.rows || j < 0 || j >= m.cols {
+		panic(fmt.Sprintf("matrix: index (%d, %d) out of range for %dx%d", i, j, m.rows, m.cols))
+	}
+}
+
+// Row returns row i as a slice of the matrix's own storage,
+// so setting an element of it sets the matrix's
+func (m *Matrix) Row(i int) []float64 {
+	if i < 0 || i >= m.rows {
+		panic(fmt.Sprintf("matrix: row %d out of range for %dx%d", i, m.rows, m.cols))
+	}
+	if m.cols == 0 {
+		return nil // a view with no columns has no storage to slice
+	}
+	start := i * m.stride
+	return m.data[start : start+m.cols : start+m.cols]
+}
+
+// Col returns column j as a rows x 1 view of the matrix,
+// a column isn't contiguous so it can't be a plain slice like Row
+func (m *Matrix) Col(j int) *Matrix {
+	return m.Slice(0, m.rows, j, j+1)
+}
+
+// Slice returns the view of rows i0 to i1 and columns j0 to j1, not
+// including i1 and j1 just like a[i:j], sharing the matrix's storage
+func (m *Matrix) Slice(i0, i1, j0, j1 int) *Matrix {
+	if i0 < 0 || i1 < i0 || i1 > m.rows || j0 < 0 || j1 < j0 || j1 > m.cols {
+		panic(fmt.Sprintf("matrix: slice [%d:%d, %d:%d] out of range for %dx%d", i0, i1, j0, j1, m.rows, m.cols))
+	}
+	v := &Matrix{rows: i1 - i0, cols: j1 - j0, stride: m.stride}
+	if v.rows > 0 && v.cols > 0 {
+		start := i0*m.stride + j0
+		v.data = m.data[start : start+(v.rows-1)*m.stride+v.cols]
+	}
+	return v
+}
+
+// Clone copies m into a new matrix that shares nothing with it
+func (m *Matrix) Clone() *Matrix {
+	c := Zeros(m.rows, m.cols)
+	for i := 0; i < m.rows; i++ {
+		copy(c.Row(i), m.Row(i))
+	}
+	return c
+}
+
+// Add returns m + b, they have to be the same size
+func (m *Matrix) Add(b *Matrix) (*Matrix, error) {
+	if m.rows != b.rows || m.cols != b.cols {
+		return nil, fmt.Errorf("%w: adding %dx%d and %dx%d", ErrShape, m.rows, m.cols, b.rows, b.cols)
+	}
+	sum := m.Clone()
+	for i := 0; i < m.rows; i++ {
+		row, brow := sum.Row(i), b.Row(i)
+		for j := range row {
+			row[j] += brow[j]
+		}
+	}
+	return sum, nil
+}
+
+// Mul returns the matrix product m b, m needs as many columns as b has rows
+func (m *Matrix) Mul(b *Matrix) (*Matrix, error) {
+	if m.cols != b.rows {
+		return nil, fmt.Errorf("%w: multiplying %dx%d by %dx%d", ErrShape, m.rows, m.cols, b.rows, b.cols)
+	}
+	p := Zeros(m.rows, b.cols)
+	for i := 0; i < m.rows; i++ {
+		prow := p.Row(i)
+		// i, k, j order walks along rows of b and p, not down their columns,
+		// so the flat storage is read in order
+		for k, a := range m.Row(i) {
+			if a == 0 {
+				continue
+			}
+			for j, v := range b.Row(k) {
+				prow[j] += a * v
+			}
+		}
+	}
+	return p, nil
+}
+
+// Transpose returns a new matrix with m's rows as its columns
+func (m *Matrix) Transpose() *Matrix {
+	t := Zeros(m.cols, m.rows)
+	for i := 0; i < m.rows; i++ {
+		for j, v := range m.Row(i) {
+			t.data[j*t.stride+i] = v
+		}
+	}
+	return t
+}
+
+// Determinant returns det(m), m has to be square
+// it eliminates down to a triangle (a copy of m, m is left alone), whose
+// determinant is its diagonal multiplied together
+// only a pivot of exactly 0 makes it 0, so a nearly singular matrix
+// gets a tiny determinant rather than none
+func (m *Matrix) Determinant() (float64, error) {
+	if m.rows != m.cols {
+		return 0, fmt.Errorf("%w: determinant of %dx%d, it has to be square", ErrShape, m.rows, m.cols)
+	}
+	a := m.Clone()
+	det := 1.0
+	for col := 0; col < a.rows; col++ {
+		p := a.pivot(col)
+		if a.data[p*a.stride+col] == 0 {
+			return 0, nil
+		}
+		if p != col {
+			a.swapRows(p, col)
+			det = -det
+		}
+		pv := a.data[col*a.stride+col]
+		det *= pv
+		for r := col + 1; r < a.rows; r++ {
+			a.addRow(r, col, -a.data[r*a.stride+col]/pv)
+		}
+	}
+	return det, nil
+}
+
+// Inverse returns the matrix that m multiplies with to give the identity,
+// m has to be square and not singular
+// it runs Gauss-Jordan elimination: the row operations that turn
+// (a copy of) m into the identity turn the identity into m's inverse
+func (m *Matrix) Inverse() (*Matrix, error) {
+	if m.rows != m.cols {
+		return nil, fmt.Errorf("%w: inverse of %dx%d, it has to be square", ErrShape, m.rows, m.cols)
+	}
+	a, inv := m.Clone(), Identity(m.rows)
+	tol := singular * m.maxAbs()
+	for col := 0; col < a.rows; col++ {
+		p := a.pivot(col)
+		if math.Abs(a.data[p*a.stride+col]) <= tol {
+			return nil, ErrSingular
+		}
+		a.swapRows(p, col)
+		inv.swapRows(p, col)
+
+		scale := 1 / a.data[col*a.stride+col]
+		a.scaleRow(col, scale)
+		inv.scaleRow(col, scale)
+		for r := 0; r < a.rows; r++ {
+			if f := -a.data[r*a.stride+col]; r != col && f != 0 {
+				a.addRow(r, col, f)
+				inv.addRow(r, col, f)
+			}
+		}
+	}
+	return inv, nil
+}
+
+// maxAbs is the biggest element of m, ignoring signs
+func (m *Matrix) maxAbs() float64 {
+	max := 0.0
+	for i := 0; i < m.rows; i++ {
+		for _, v := range m.Row(i) {
+			if math.Abs(v) > max {
+				max = math.Abs(v)
+			}
+		}
+	}
+	return max
+}
+
+// pivot is the row at or below col with the biggest value in column col,
+// dividing by the biggest keeps the rounding errors small
+func (m *Matrix) pivot(col int) int {
+	best := col
+	for r := col + 1; r < m.rows; r++ {
+		if math.Abs(m.data[r*m.stride+col]) > math.Abs(m.data[best*m.stride+col]) {
+			best = r
+		}
+	}
+	return best
+}
+
+func (m *Matrix) swapRows(a, b int) {
+	if a == b {
+		return
+	}
+	ra, rb := m.Row(a), m.Row(b)
+	for j := range ra {
+		ra[j], rb[j] = rb[j], ra[j]
+	}
+}
+
+func (m *Matrix) scaleRow(r int, f float64) {
+	row := m.Row(r)
+	for j := range row {
+		row[j] *= f
+	}
+}
+
+// addRow adds f times row src to row dst
+func (m *Matrix) addRow(dst, src int, f float64) {
+	d, s := m.Row(dst), m.Row(src)
+	for j := range d {
+		d[j] += f * s[j]
+	}
+}
+
+// Equal reports whether m and b are the same size and
+// no two of their elements are more than tol apart
+func (m *Matrix) Equal(b *Matrix, tol float64) bool {
+	if m.rows != b.rows || m.cols != b.cols {
+		return false
+	}
+	for i := 0; i < m.rows; i++ {
+		brow := b.Row(i)
+		for j, v := range m.Row(i) {
+			if math.Abs(v-brow[j]) > tol {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String prints m a row per line with its columns lined up,
+// numbers rounded to 6 significant figures:
+//
+//	⎡ 1  0  0⎤
+//	⎢ 0  1  0⎥
+//	⎣ 0  0  1⎦
+func (m *Matrix) String() string {
+	if m.rows == 0 || m.cols == 0 {
+		return fmt.Sprintf("[%dx%d]", m.rows, m.cols)
+	}
+
+	cells := make([][]string, m.rows)
+	width := make([]int, m.cols)
+	for i := range cells {
+		cells[i] = make([]string, m.cols)
+		for j, v := range m.Row(i) {
+			if v == 0 {
+				v = 0 // no -0
+			}
+			s := strconv.FormatFloat(v, 'g', 6, 64)
+			cells[i][j] = s
+			if len(s) > width[j] {
+				width[j] = len(s)
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i, row := range cells {
+		left, right := "⎢", "⎥"
+		switch {
+		case m.rows == 1:
+			left, right = "[", "]"
+		case i == 0:
+			left, right = "⎡", "⎤"
+		case i == m.rows-1:
+			left, right = "⎣", "⎦"
+		}
+		b.WriteString(left)
+		for j, s := range row {
+			fmt.Fprintf(&b, " %*s", width[j], s)
+		}
+		b.WriteString(right)
+		if i < m.rows-1 {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,360 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func mustRows(t testing.TB, rows [][]float64) *Matrix {
+	t.Helper()
+	m, err := FromRows(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestConstructors(t *testing.T) {
+	id := Identity(3)
+	want := mustRows(t, [][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}})
+	if !id.Equal(want, 0) {
+		t.Errorf("Identity(3) =\n%v", id)
+	}
+	if r, c := Zeros(2, 5).Dims(); r != 2 || c != 5 {
+		t.Errorf("Zeros(2, 5).Dims() = %d, %d", r, c)
+	}
+	if _, err := FromRows([][]float64{{1, 2}, {3}}); !errors.Is(err, ErrShape) {
+		t.Errorf("FromRows of ragged rows error = %v, want ErrShape", err)
+	}
+
+	// FromRows copies, so the rows given can change afterwards
+	rows := [][]float64{{1, 2}}
+	m := mustRows(t, rows)
+	rows[0][0] = 99
+	if m.At(0, 0) != 1 {
+		t.Error("FromRows shares storage with its argument")
+	}
+}
+
+func TestViewsShareStorage(t *testing.T) {
+	m := mustRows(t, [][]float64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}})
+
+	row := m.Row(1)
+	row[0] = 40
+	if m.At(1, 0) != 40 {
+		t.Error("writing to Row(1) didn't change the matrix")
+	}
+	if _ = append(row, 1); m.At(2, 0) != 7 {
+		t.Error("appending to a Row overwrote the next row")
+	}
+
+	col := m.Col(2)
+	if r, c := col.Dims(); r != 3 || c != 1 || col.At(2, 0) != 9 {
+		t.Errorf("Col(2) =\n%v", col)
+	}
+	col.Set(0, 0, 30)
+	if m.At(0, 2) != 30 {
+		t.Error("writing to Col(2) didn't change the matrix")
+	}
+
+	sub := m.Slice(1, 3, 1, 3)
+	if !sub.Equal(mustRows(t, [][]float64{{5, 6}, {8, 9}}), 0) {
+		t.Errorf("Slice(1, 3, 1, 3) =\n%v", sub)
+	}
+	sub.Set(1, 1, 90)
+	if m.At(2, 2) != 90 {
+		t.Error("writing to a Slice didn't change the matrix")
+	}
+	// a view of a view still lands in the original
+	sub.Slice(0, 1, 0, 2).Set(0, 0, 50)
+	if m.At(1, 1) != 50 {
+		t.Error("writing to a slice of a slice didn't change the matrix")
+	}
+
+	c := m.Clone()
+	c.Set(0, 0, -1)
+	if m.At(0, 0) != 1 {
+		t.Error("writing to a Clone changed the matrix")
+	}
+	if r, cols := m.Slice(1, 1, 0, 3).Dims(); r != 0 || cols != 3 {
+		t.Errorf("empty Slice dims = %d, %d", r, cols)
+	}
+}
+
+func TestPanics(t *testing.T) {
+	m := Zeros(2, 2)
+	for name, f := range map[string]func(){
+		"At":    func() { m.At(2, 0) },
+		"Set":   func() { m.Set(0, -1, 1) },
+		"Row":   func() { m.Row(2) },
+		"Slice": func() { m.Slice(0, 3, 0, 1) },
+		"Zeros": func() { Zeros(-1, 1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s out of range didn't panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a := mustRows(t, [][]float64{{1, 2, 3}, {4, 5, 6}})
+	b := mustRows(t, [][]float64{{7, 8}, {9, 10}, {11, 12}})
+
+	p, err := a.Mul(b)
+	if err != nil || !p.Equal(mustRows(t, [][]float64{{58, 64}, {139, 154}}), 0) {
+		t.Errorf("a.Mul(b) =\n%v, %v", p, err)
+	}
+	if _, err := a.Mul(a); !errors.Is(err, ErrShape) {
+		t.Errorf("2x3 times 2x3 error = %v, want ErrShape", err)
+	}
+
+	s, err := a.Add(b.Transpose())
+	if err != nil || !s.Equal(mustRows(t, [][]float64{{8, 11, 14}, {12, 15, 18}}), 0) {
+		t.Errorf("a.Add(b transposed) =\n%v, %v", s, err)
+	}
+	if _, err := a.Add(b); !errors.Is(err, ErrShape) {
+		t.Errorf("2x3 plus 3x2 error = %v, want ErrShape", err)
+	}
+
+	// multiplying by the identity changes nothing
+	if p, _ := a.Mul(Identity(3)); !p.Equal(a, 0) {
+		t.Errorf("a times I =\n%v", p)
+	}
+}
+
+// views with no rows or no columns work like Zeros of the same size
+func TestEmptyViews(t *testing.T) {
+	for _, v := range []*Matrix{Identity(3).Slice(0, 2, 1, 1), Identity(3).Slice(1, 1, 0, 3), Identity(3).Slice(3, 3, 3, 3)} {
+		r, c := v.Dims()
+		c0 := v.Clone()
+		if !c0.Equal(Zeros(r, c), 0) || !v.Equal(c0, 0) {
+			t.Errorf("%dx%d view cloned to %v", r, c, c0)
+		}
+		if s, err := v.Add(Zeros(r, c)); err != nil || !s.Equal(v, 0) {
+			t.Errorf("%dx%d view plus zeros = %v, %v", r, c, s, err)
+		}
+		if p, err := v.Mul(Zeros(c, 4)); err != nil || !p.Equal(Zeros(r, 4), 0) {
+			t.Errorf("%dx%d view times %dx4 = %v, %v", r, c, c, p, err)
+		}
+		if tr := v.Transpose(); !tr.Equal(Zeros(c, r), 0) {
+			t.Errorf("%dx%d view transposed to %v", r, c, tr)
+		}
+		if got, want := v.String(), fmt.Sprintf("[%dx%d]", r, c); got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+		for i := 0; i < r; i++ {
+			if row := v.Row(i); len(row) != 0 {
+				t.Errorf("Row(%d) of a %dx%d view = %v", i, r, c, row)
+			}
+		}
+	}
+}
+
+func TestDeterminantAndInverse(t *testing.T) {
+	tests := []struct {
+		rows [][]float64
+		det  float64
+	}{
+		{[][]float64{{4}}, 4},
+		{[][]float64{{1, 2}, {3, 4}}, -2},
+		{[][]float64{{0, 1}, {1, 0}}, -1}, // needs a row swap
+		{[][]float64{{2, -3, 1}, {2, 0, -1}, {1, 4, 5}}, 49},
+		{[][]float64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, 0},
+		{[][]float64{}, 1},
+	}
+	for _, tt := range tests {
+		m := mustRows(t, tt.rows)
+		det, err := m.Determinant()
+		if err != nil || math.Abs(det-tt.det) > 1e-9 {
+			t.Errorf("Determinant of\n%v = %v, %v, want %v", m, det, err, tt.det)
+		}
+
+		inv, err := m.Inverse()
+		if tt.det == 0 {
+			if !errors.Is(err, ErrSingular) {
+				t.Errorf("Inverse of singular\n%v error = %v, want ErrSingular", m, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Inverse of\n%v error = %v", m, err)
+			continue
+		}
+		r, _ := m.Dims()
+		if p, _ := m.Mul(inv); !p.Equal(Identity(r), 1e-9) {
+			t.Errorf("m times its inverse =\n%v", p)
+		}
+	}
+
+	// a view inverts like the matrix it shows
+	big := mustRows(t, [][]float64{{9, 9, 9}, {9, 1, 2}, {9, 3, 4}})
+	inv, err := big.Slice(1, 3, 1, 3).Inverse()
+	if err != nil || !inv.Equal(mustRows(t, [][]float64{{-2, 1}, {1.5, -0.5}}), 1e-9) {
+		t.Errorf("Inverse of a view =\n%v, %v", inv, err)
+	}
+
+	// singular doesn't depend on the scale: tiny and huge matrices invert
+	// fine, while multiples of a singular one still don't
+	for _, scale := range []float64{1e-13, 1e-100, 1e100} {
+		m := mustRows(t, [][]float64{{scale, 0}, {0, scale}})
+		if det, err := m.Determinant(); err != nil || det != scale*scale {
+			t.Errorf("Determinant of diag(%g, %g) = %v, %v", scale, scale, det, err)
+		}
+		inv, err := m.Inverse()
+		if err != nil || !inv.Equal(mustRows(t, [][]float64{{1 / scale, 0}, {0, 1 / scale}}), 1e-9/scale) {
+			t.Errorf("Inverse of diag(%g, %g) =\n%v, %v", scale, scale, inv, err)
+		}
+		flat := mustRows(t, [][]float64{{scale, 2 * scale}, {2 * scale, 4 * scale}})
+		if _, err := flat.Inverse(); !errors.Is(err, ErrSingular) {
+			t.Errorf("Inverse of a singular matrix scaled by %g error = %v, want ErrSingular", scale, err)
+		}
+	}
+	if _, err := Zeros(2, 2).Inverse(); !errors.Is(err, ErrSingular) {
+		t.Errorf("Inverse of zeros error = %v, want ErrSingular", err)
+	}
+
+	if _, err := Zeros(2, 3).Determinant(); !errors.Is(err, ErrShape) {
+		t.Errorf("Determinant of 2x3 error = %v, want ErrShape", err)
+	}
+	if _, err := Zeros(2, 3).Inverse(); !errors.Is(err, ErrShape) {
+		t.Errorf("Inverse of 2x3 error = %v, want ErrShape", err)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		m    *Matrix
+		want string
+	}{
+		{Identity(3), "⎡ 1 0 0⎤\n⎢ 0 1 0⎥\n⎣ 0 0 1⎦"},
+		{mustRows(t, [][]float64{{1.5, -20}, {300, 0.1 + 0.2}}), "⎡ 1.5 -20⎤\n⎣ 300 0.3⎦"},
+		{mustRows(t, [][]float64{{1, 2}}), "[ 1 2]"},
+		{mustRows(t, [][]float64{{-0.0}}), "[ 0]"},
+		{Zeros(0, 3), "[0x3]"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("String() =\n%s\nwant\n%s", got, tt.want)
+		}
+	}
+}
+
+// the lesson's identityMatrix is a [3][3]int, an array of arrays
+// these benchmarks multiply n x n float64 matrices laid out that way,
+// as a slice of row slices, and as one flat Matrix
+const n = 64
+
+type grid [n][n]float64
+
+// mulArrays takes and returns its arrays by value, so every call
+// copies both arguments and the result, 3 x 32KB
+func mulArrays(a, b grid) grid {
+	var p grid
+	for i := 0; i < n; i++ {
+		for k := 0; k < n; k++ {
+			for j := 0; j < n; j++ {
+				p[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return p
+}
+
+// mulArrayPointers is the same without the copies
+func mulArrayPointers(a, b, p *grid) {
+	*p = grid{}
+	for i := 0; i < n; i++ {
+		for k := 0; k < n; k++ {
+			for j := 0; j < n; j++ {
+				p[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+}
+
+func mulSlices(a, b [][]float64) [][]float64 {
+	p := make([][]float64, n)
+	for i := range p {
+		p[i] = make([]float64, n)
+		for k := 0; k < n; k++ {
+			for j := 0; j < n; j++ {
+				p[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return p
+}
+
+func benchGrid() *grid {
+	var g grid
+	for i := range g {
+		for j := range g[i] {
+			g[i][j] = float64(i*n + j)
+		}
+	}
+	return &g
+}
+
+func benchRows() [][]float64 {
+	g := benchGrid()
+	rows := make([][]float64, n)
+	for i := range rows {
+		rows[i] = g[i][:]
+	}
+	return rows
+}
+
+// sink keeps the compiler from optimising the copies away
+var sink grid
+
+func BenchmarkMulArrayValues(b *testing.B) {
+	g := benchGrid()
+	for i := 0; i < b.N; i++ {
+		sink = mulArrays(*g, *g)
+	}
+}
+
+func BenchmarkMulArrayPointers(b *testing.B) {
+	g := benchGrid()
+	var p grid
+	for i := 0; i < b.N; i++ {
+		mulArrayPointers(g, g, &p)
+	}
+}
+
+func BenchmarkMulSliceOfSlices(b *testing.B) {
+	rows := benchRows()
+	for i := 0; i < b.N; i++ {
+		mulSlices(rows, rows)
+	}
+}
+
+func BenchmarkMulFlat(b *testing.B) {
+	m := mustRows(b, benchRows())
+	for i := 0; i < b.N; i++ {
+		m.Mul(m)
+	}
+}
+
+// copying an array of arrays copies every element, taking a view copies none
+func BenchmarkCopyArray(b *testing.B) {
+	g := benchGrid()
+	for i := 0; i < b.N; i++ {
+		g[0][0] = float64(i)
+		sink = *g
+	}
+}
+
+func BenchmarkSliceView(b *testing.B) {
+	m := mustRows(b, benchRows())
+	for i := 0; i < b.N; i++ {
+		m.Slice(0, n, 0, n)
+	}
+}
@@ -0,0 +1,212 @@
+package golearn
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// FailurePolicy is what a MultiWriter does when one of its writers fails
+type FailurePolicy int
+
+const (
+	// FailFast stops at the first writer that fails and returns its error,
+	// the writers after it don't get the data (when writing in parallel
+	// they all do, and the error is the first failing writer's)
+	FailFast FailurePolicy = iota
+	// BestEffort writes to every writer and returns a MultiWriteError
+	// holding every failure
+	BestEffort
+	// RemoveFailing writes to every writer and drops the ones that fail,
+	// the write only fails if no writer took it
+	RemoveFailing
+)
+
+// ErrNoWriters is returned by a MultiWriter with no writers left
+var ErrNoWriters = errors.New("multi writer has no writers")
+
+// WriterError is one writer failing inside a MultiWriter
+type WriterError struct {
+	Index  int    // position of the writer in the MultiWriter, from 0
+	Writer Writer // the writer that failed
+	Err    error
+}
+
+func (e *WriterError) Error() string {
+	return fmt.Sprintf("writer %d (%T): %v", e.Index, e.Writer, e.Err)
+}
+
+// Unwrap returns the writer's error
+func (e *WriterError) Unwrap() error { return e.Err }
+
+// MultiWriteError is every writer that failed in one call, in writer order
+type MultiWriteError []*WriterError
+
+func (e MultiWriteError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d writers failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Is reports whether any of the writers' errors is target
+func (e MultiWriteError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriterStats counts what a MultiWriter has done with one of its writers
+type WriterStats struct {
+	Writer  Writer
+	Writes  int   // calls that succeeded
+	Bytes   int64 // bytes written, failed calls included
+	Errors  int   // calls that failed
+	LastErr error // the most recent failure, nil if there hasn't been one
+	Removed bool  // dropped by RemoveFailing
+}
+
+// MultiWriter is a Writer that writes everything it is given to several
+// Writers, like io.MultiWriter but with a say in what happens when one
+// of them fails, see FailurePolicy
+// set Policy and Parallel before the first Write
+// a MultiWriter is safe to use from many goroutines at once, writes
+// reach every writer in the same order
+type MultiWriter struct {
+	Policy FailurePolicy
+	// Parallel writes to every writer at once, each from its own goroutine,
+	// rather than one after the other
+	Parallel bool
+
+	mu     sync.Mutex
+	stats  []WriterStats
+	closed bool
+}
+
+// NewMultiWriter makes a FailFast MultiWriter writing to writers in turn
+func NewMultiWriter(writers ...Writer) *MultiWriter {
+	mw := &MultiWriter{stats: make([]WriterStats, len(writers))}
+	for i, w := range writers {
+		mw.stats[i].Writer = w
+	}
+	return mw
+}
+
+// Write writes data to every writer, and returns len(data) when they all
+// took it, otherwise see Policy
+func (mw *MultiWriter) Write(data []byte) (int, error) {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	if mw.closed {
+		return 0, ErrWriterClosed
+	}
+
+	var live []int
+	for i := range mw.stats {
+		if !mw.stats[i].Removed {
+			live = append(live, i)
+		}
+	}
+	if len(live) == 0 {
+		return 0, ErrNoWriters
+	}
+
+	errs := make([]error, len(mw.stats))
+	if mw.Parallel {
+		var wg sync.WaitGroup
+		for _, i := range live {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = mw.writeTo(i, data)
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for _, i := range live {
+			errs[i] = mw.writeTo(i, data)
+			if errs[i] != nil && mw.Policy == FailFast {
+				break
+			}
+		}
+	}
+
+	var failed MultiWriteError
+	for _, i := range live {
+		if errs[i] != nil {
+			failed = append(failed, &WriterError{Index: i, Writer: mw.stats[i].Writer, Err: errs[i]})
+		}
+	}
+	if len(failed) == 0 {
+		return len(data), nil
+	}
+
+	switch mw.Policy {
+	case FailFast:
+		return 0, failed[0]
+	case RemoveFailing:
+		for _, err := range failed {
+			mw.stats[err.Index].Removed = true
+		}
+		if len(failed) < len(live) {
+			return len(data), nil
+		}
+	}
+	return 0, failed
+}
+
+// writeTo writes data to the i'th writer and counts how it went
+// each goroutine only touches its own writer's stats, so Parallel is safe
+func (mw *MultiWriter) writeTo(i int, data []byte) error {
+	s := &mw.stats[i]
+	n, err := s.Writer.Write(data)
+	if err == nil && n < len(data) {
+		err = io.ErrShortWrite
+	}
+	s.Bytes += int64(n)
+	if err != nil {
+		s.Errors++
+		s.LastErr = err
+		return err
+	}
+	s.Writes++
+	return nil
+}
+
+// Stats returns a copy of every writer's stats, in writer order
+func (mw *MultiWriter) Stats() []WriterStats {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	return append([]WriterStats(nil), mw.stats...)
+}
+
+// Close closes every writer that is also a Closer, removed ones included,
+// and returns a MultiWriteError if any of them failed
+// closing twice does nothing
+func (mw *MultiWriter) Close() error {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	if mw.closed {
+		return nil
+	}
+	mw.closed = true
+
+	var failed MultiWriteError
+	for i, s := range mw.stats {
+		if c, ok := s.Writer.(Closer); ok {
+			if err := c.Close(); err != nil {
+				failed = append(failed, &WriterError{Index: i, Writer: s.Writer, Err: err})
+			}
+		}
+	}
+	if len(failed) != 0 {
+		return failed
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package golearn
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// failAfter is a writer that works for its first ok writes and then fails
+type failAfter struct {
+	mu  sync.Mutex
+	ok  int
+	out bytes.Buffer
+}
+
+var errBroken = errors.New("broken writer")
+
+func (f *failAfter) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.ok == 0 {
+		return 0, errBroken
+	}
+	f.ok--
+	return f.out.Write(p)
+}
+
+func (f *failAfter) String() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.out.String()
+}
+
+// closeCounter counts its Close calls
+type closeCounter struct {
+	bytes.Buffer
+	closes int
+}
+
+func (c *closeCounter) Close() error {
+	c.closes++
+	return nil
+}
+
+func TestMultiWriterAllWriterKinds(t *testing.T) {
+	checkLeaks(t)
+	var console bytes.Buffer
+	sink, err := newTCPSink()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buffered bytes.Buffer
+
+	for _, parallel := range []bool{false, true} {
+		console.Reset()
+		buffered.Reset()
+		tw := NewTCPWriter(sink.Addr())
+		fw := NewFileWriter(filepath.Join(t.TempDir(), "multi.log"))
+		mw := NewMultiWriter(ConsoleWriter{Out: &console}, tw, fw, NewBufferedWriterCloser(&buffered, 0))
+		mw.Parallel = parallel
+		if n, err := mw.Write([]byte("hello")); n != 5 || err != nil {
+			t.Fatalf("parallel %v: Write() = %d, %v, want 5, nil", parallel, n, err)
+		}
+		// Close closes the TCPWriter, FileWriter and BufferedWriterCloser,
+		// which flushes the buffer
+		if err := mw.Close(); err != nil {
+			t.Fatalf("parallel %v: Close() error = %v", parallel, err)
+		}
+		if console.String() != "hello\n" || buffered.String() != "hello" {
+			t.Errorf("parallel %v: console got %q and buffer %q, want hello", parallel, console.String(), buffered.String())
+		}
+		if data, err := ioutil.ReadFile(fw.Path); err != nil || string(data) != "hello" {
+			t.Errorf("parallel %v: file holds %q, %v, want %q", parallel, data, err, "hello")
+		}
+		if _, err := mw.Write([]byte("too late")); !errors.Is(err, ErrWriterClosed) {
+			t.Errorf("parallel %v: Write() after Close error = %v, want %v", parallel, err, ErrWriterClosed)
+		}
+	}
+
+	if got := sink.Close(); got != "hellohello" {
+		t.Errorf("tcp server got %q, want %q", got, "hellohello")
+	}
+}
+
+func TestMultiWriterPolicies(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		// FailFast stops at the broken writer
+		before, broken, after := &failAfter{ok: 10}, &failAfter{ok: 1}, &failAfter{ok: 10}
+		mw := NewMultiWriter(before, broken, after)
+		mw.Parallel = parallel
+		mw.Write([]byte("a"))
+		n, err := mw.Write([]byte("b"))
+		var werr *WriterError
+		if n != 0 || !errors.As(err, &werr) || werr.Index != 1 || !errors.Is(err, errBroken) {
+			t.Errorf("parallel %v: FailFast Write() = %d, %v, want writer 1's error", parallel, n, err)
+		}
+		if expected := "ab"; before.String() != expected {
+			t.Errorf("parallel %v: FailFast writer before the broken one got %q, want %q", parallel, before.String(), expected)
+		}
+		if expected := map[bool]string{false: "a", true: "ab"}[parallel]; after.String() != expected {
+			t.Errorf("parallel %v: FailFast writer after the broken one got %q, want %q", parallel, after.String(), expected)
+		}
+
+		// BestEffort tells us about every failure but writes to the rest
+		first, second, fine := &failAfter{}, &failAfter{}, &failAfter{ok: 10}
+		mw = NewMultiWriter(first, second, fine)
+		mw.Policy, mw.Parallel = BestEffort, parallel
+		_, err = mw.Write([]byte("x"))
+		var merr MultiWriteError
+		if !errors.As(err, &merr) || len(merr) != 2 || merr[0].Index != 0 || merr[1].Index != 1 || !errors.Is(err, errBroken) {
+			t.Errorf("parallel %v: BestEffort Write() error = %v, want writers 0 and 1 failing", parallel, err)
+		}
+		if fine.String() != "x" {
+			t.Errorf("parallel %v: BestEffort working writer got %q, want %q", parallel, fine.String(), "x")
+		}
+
+		// RemoveFailing drops the broken writer and carries on
+		broken, fine = &failAfter{ok: 1}, &failAfter{ok: 10}
+		mw = NewMultiWriter(broken, fine)
+		mw.Policy, mw.Parallel = RemoveFailing, parallel
+		for _, s := range []string{"1", "2", "3"} {
+			if n, err := mw.Write([]byte(s)); n != 1 || err != nil {
+				t.Errorf("parallel %v: RemoveFailing Write(%q) = %d, %v, want 1, nil", parallel, s, n, err)
+			}
+		}
+		stats := mw.Stats()
+		if !stats[0].Removed || stats[0].Writes != 1 || stats[0].Errors != 1 || stats[0].LastErr != errBroken {
+			t.Errorf("parallel %v: broken writer stats = %+v", parallel, stats[0])
+		}
+		if stats[1].Removed || stats[1].Writes != 3 || stats[1].Bytes != 3 || stats[1].Errors != 0 {
+			t.Errorf("parallel %v: working writer stats = %+v", parallel, stats[1])
+		}
+		mw = NewMultiWriter(&failAfter{})
+		mw.Policy = RemoveFailing
+		if _, err := mw.Write([]byte("x")); !errors.Is(err, errBroken) {
+			t.Errorf("parallel %v: RemoveFailing with every writer failing error = %v, want %v", parallel, err, errBroken)
+		}
+		if _, err := mw.Write([]byte("x")); err != ErrNoWriters {
+			t.Errorf("parallel %v: Write() with every writer removed error = %v, want %v", parallel, err, ErrNoWriters)
+		}
+	}
+}
+
+func TestMultiWriterClosesOnce(t *testing.T) {
+	c := new(closeCounter)
+	mw := NewMultiWriter(c, &failAfter{})
+	mw.Policy = RemoveFailing
+	mw.Write([]byte("x"))
+	mw.Close()
+	mw.Close()
+	if c.closes != 1 {
+		t.Errorf("closer closed %d times, want 1", c.closes)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !race
+// +build !race
+
+package golearn
+
+// raceEnabled reports whether we were built with -race, see race.go
+const raceEnabled = false
@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package golearn
+
+// raceEnabled reports whether we were built with -race, in which case
+// the lessons skip the data races they would otherwise show off
+const raceEnabled = true
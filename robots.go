@@ -0,0 +1,50 @@
+package golearn
+
+import (
+	"io"
+	"net/http"
+)
+
+// DefaultRobotsURL is the robots.txt DeferPanicRecover fetches
+// when its Env doesn't point it somewhere else
+const DefaultRobotsURL = "http://www.google.com/robots.txt"
+
+// SampleRobotsTxt is the robots.txt served by RobotsHandler,
+// a trimmed down copy of the real one at DefaultRobotsURL
+const SampleRobotsTxt = `User-agent: *
+Disallow: /search
+Allow: /search/about
+Allow: /search/howsearchworks
+Disallow: /sdch
+Disallow: /groups
+Disallow: /index.html?
+Disallow: /?
+Allow: /?hl=
+Disallow: /?hl=*&
+
+Sitemap: https://www.google.com/sitemap.xml
+`
+
+// Fetcher is the part of *http.Client DeferPanicRecover needs,
+// so a lesson can be pointed at something other than the internet
+type Fetcher interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// RobotsHandler serves SampleRobotsTxt at /robots.txt, run it with
+// httptest.NewServer (the tests and cmd/golearn do) and hand the server
+// to Env.UseRobots to run DeferPanicRecover without the internet
+func RobotsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, SampleRobotsTxt)
+	})
+	return mux
+}
+
+// UseRobots points env's DeferPanicRecover fetch at url, fetched with client
+func (e *Env) UseRobots(url string, client *http.Client) {
+	e.Fetcher = client
+	e.RobotsURL = url
+}
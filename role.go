@@ -0,0 +1,139 @@
+package golearn
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Role is the Constants lesson's packed permission byte grown into a type:
+// a set of flags, one bit each, backed by a uint64 so there's room for 64
+// roles print as their flag names joined by |, e.g. "admin|financials|europe",
+// and ParseRole reads that back, which is also how they marshal to
+// text and JSON
+type Role uint64
+
+// the roles, in bit order
+const (
+	RoleAdmin Role = 1 << iota
+	RoleHeadquarters
+	RoleFinancials
+
+	RoleAfrica
+	RoleAsia
+	RoleEurope
+	RoleNorthAmerica
+	RoleSouthAmerica
+	RoleOceania
+	RoleAntarctica
+)
+
+// RoleNone has no flags set
+const RoleNone Role = 0
+
+// roleNames is each flag's name, by bit
+var roleNames = [...]string{
+	"admin",
+	"headquarters",
+	"financials",
+	"africa",
+	"asia",
+	"europe",
+	"north-america",
+	"south-america",
+	"oceania",
+	"antarctica",
+}
+
+// ErrUnknownRole is returned by ParseRole for a name that isn't a role
+var ErrUnknownRole = errors.New("unknown role")
+
+// Has reports whether every flag in flags is set in r
+func (r Role) Has(flags Role) bool { return r&flags == flags }
+
+// Set turns the flags on
+func (r *Role) Set(flags Role) { *r |= flags }
+
+// Clear turns the flags off
+func (r *Role) Clear(flags Role) { *r &^= flags }
+
+// Toggle flips the flags, on ones go off and off ones go on
+func (r *Role) Toggle(flags Role) { *r ^= flags }
+
+// Flags splits r into its single flags, lowest bit first
+func (r Role) Flags() []Role {
+	var flags []Role
+	for rest := uint64(r); rest != 0; rest &= rest - 1 {
+		flags = append(flags, Role(1)<<uint(bits.TrailingZeros64(rest)))
+	}
+	return flags
+}
+
+// String names r's flags joined by |, "none" when there aren't any
+// bits without a name are printed in hex, e.g. "admin|0x8000"
+func (r Role) String() string {
+	if r == RoleNone {
+		return "none"
+	}
+	names := make([]string, 0, bits.OnesCount64(uint64(r)))
+	for _, flag := range r.Flags() {
+		i := bits.TrailingZeros64(uint64(flag))
+		if i < len(roleNames) {
+			names = append(names, roleNames[i])
+		} else {
+			names = append(names, fmt.Sprintf("%#x", uint64(flag)))
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// ParseRole reads roles the way String writes them: flag names joined by |,
+// in any order and any case, with spaces allowed around them
+// "none" and "" are no roles, and a hex number like 0x8000 is taken as is
+func ParseRole(s string) (Role, error) {
+	if s = strings.TrimSpace(s); s == "" || strings.EqualFold(s, "none") {
+		return RoleNone, nil
+	}
+
+	var r Role
+	for _, name := range strings.Split(s, "|") {
+		flag, err := parseFlag(strings.TrimSpace(name))
+		if err != nil {
+			return RoleNone, fmt.Errorf("parsing role %q: %w", s, err)
+		}
+		r |= flag
+	}
+	return r, nil
+}
+
+func parseFlag(name string) (Role, error) {
+	for i, n := range roleNames {
+		if strings.EqualFold(name, n) {
+			return Role(1) << uint(i), nil
+		}
+	}
+	if strings.HasPrefix(name, "0x") || strings.HasPrefix(name, "0X") {
+		if n, err := strconv.ParseUint(name[2:], 16, 64); err == nil {
+			return Role(n), nil
+		}
+	}
+	return RoleNone, fmt.Errorf("%w %q", ErrUnknownRole, name)
+}
+
+// MarshalText writes r the way String does,
+// encoding/json uses it too, so a Role is a JSON string
+func (r Role) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText reads r with ParseRole
+func (r *Role) UnmarshalText(text []byte) error {
+	parsed, err := ParseRole(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
@@ -0,0 +1,94 @@
+package golearn
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRoleFlags(t *testing.T) {
+	var r Role
+	r.Set(RoleAdmin | RoleEurope)
+	if !r.Has(RoleAdmin) || !r.Has(RoleAdmin|RoleEurope) || r.Has(RoleAdmin|RoleAsia) {
+		t.Errorf("Has is wrong for %v", r)
+	}
+	r.Clear(RoleAdmin)
+	if r != RoleEurope {
+		t.Errorf("after Clear(admin) r = %v, want europe", r)
+	}
+	r.Toggle(RoleEurope | RoleAntarctica)
+	if r != RoleAntarctica {
+		t.Errorf("after Toggle(europe|antarctica) r = %v, want antarctica", r)
+	}
+	if got := (RoleAdmin | RoleAsia | RoleAntarctica).Flags(); !reflect.DeepEqual(got, []Role{RoleAdmin, RoleAsia, RoleAntarctica}) {
+		t.Errorf("Flags() = %v", got)
+	}
+	// more flags than fit in the lesson's byte
+	if RoleAntarctica <= 0xff {
+		t.Errorf("RoleAntarctica = %#x, want it past 8 bits", uint64(RoleAntarctica))
+	}
+}
+
+func TestRoleString(t *testing.T) {
+	tests := []struct {
+		r    Role
+		want string
+	}{
+		{RoleNone, "none"},
+		{RoleAdmin, "admin"},
+		{RoleAdmin | RoleFinancials | RoleEurope, "admin|financials|europe"},
+		{RoleNorthAmerica | RoleOceania, "north-america|oceania"},
+		{RoleAdmin | 1<<40, "admin|0x10000000000"},
+		{1 << 63, "0x8000000000000000"},
+	}
+	for _, tt := range tests {
+		if got := tt.r.String(); got != tt.want {
+			t.Errorf("Role(%#x).String() = %q, want %q", uint64(tt.r), got, tt.want)
+		}
+		if back, err := ParseRole(tt.want); err != nil || back != tt.r {
+			t.Errorf("ParseRole(%q) = %v, %v, want %v", tt.want, back, err, tt.r)
+		}
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	for s, want := range map[string]Role{
+		"":                  RoleNone,
+		"NONE":              RoleNone,
+		" Europe | ADMIN ":  RoleAdmin | RoleEurope,
+		"asia|asia":         RoleAsia,
+		"south-america|0x2": RoleSouthAmerica | RoleHeadquarters,
+	} {
+		if got, err := ParseRole(s); err != nil || got != want {
+			t.Errorf("ParseRole(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"king", "admin|", "admin||europe", "0xzz", "financials|0x"} {
+		if r, err := ParseRole(s); !errors.Is(err, ErrUnknownRole) {
+			t.Errorf("ParseRole(%q) = %v, %v, want ErrUnknownRole", s, r, err)
+		}
+	}
+}
+
+func TestRoleJSON(t *testing.T) {
+	type user struct {
+		Name  string
+		Roles Role
+	}
+	in := user{"Ada", RoleHeadquarters | RoleFinancials | RoleAntarctica}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Name":"Ada","Roles":"headquarters|financials|antarctica"}`; string(data) != want {
+		t.Errorf("json.Marshal = %s, want %s", data, want)
+	}
+	var out user
+	if err := json.Unmarshal(data, &out); err != nil || out != in {
+		t.Errorf("json.Unmarshal(%s) = %+v, %v", data, out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"Roles":"admin|wizard"}`), &out); !errors.Is(err, ErrUnknownRole) {
+		t.Errorf("json.Unmarshal of an unknown role = %v, want ErrUnknownRole", err)
+	}
+}
@@ -0,0 +1,181 @@
+package golearn
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timeoutGrace is how long RunWithTimeout lets a lesson wind down
+// after its deadline before deciding it is stuck
+// leakGrace is how long a finished lesson's goroutines get to exit
+// before they count as leaked
+const (
+	timeoutGrace = 100 * time.Millisecond
+	leakGrace    = 100 * time.Millisecond
+)
+
+// RunWithTimeout runs l like l.Run, but gives up on it after timeout
+// (no limit when timeout <= 0) or once ctx is done, whichever comes first
+// a lesson that overruns gets a *TimeoutError listing the goroutines it
+// left behind, and anything they print after that is thrown away, so the
+// caller can carry on with the next lesson
+// a lesson that finishes in time but leaves goroutines running gets
+// a *LeakError listing them
+// goroutines are process wide, so the leak check can blame a lesson for
+// goroutines some other code started meanwhile: run lessons one at a time
+func RunWithTimeout(ctx context.Context, l Lesson, w io.Writer, env *Env, timeout time.Duration) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	before := goroutines()
+	gw := &gateWriter{w: output(w)}
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Run(ctx, gw, env)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+		if leaked := leakedGoroutines(before, leakGrace); len(leaked) != 0 {
+			gw.close()
+			return &LeakError{Lesson: l.Name(), Goroutines: leaked}
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	// a lesson watching ctx returns soon after it is done,
+	// one that doesn't is stuck and we stop listening to it
+	var err error
+	returned := false
+	select {
+	case err = <-done:
+		returned = true
+	case <-time.After(timeoutGrace):
+	}
+	gw.close()
+
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		if returned {
+			return err
+		}
+		return lessonError(l.Name(), ErrCanceled, "running", ctx.Err())
+	}
+	return &TimeoutError{
+		Lesson:     l.Name(),
+		Timeout:    timeout,
+		Goroutines: newGoroutines(before),
+		Err:        err,
+	}
+}
+
+// gateWriter passes writes through to w until it is closed,
+// then quietly drops them
+type gateWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closed bool
+}
+
+func (g *gateWriter) Write(p []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return len(p), nil
+	}
+	return g.w.Write(p)
+}
+
+func (g *gateWriter) close() {
+	g.mu.Lock()
+	g.closed = true
+	g.mu.Unlock()
+}
+
+// goroutines returns the stack of every goroutine, keyed by goroutine id
+func goroutines() map[string]string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := make(map[string]string)
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		// each stack starts "goroutine 18 [chan receive]:"
+		fields := strings.Fields(g)
+		if len(fields) >= 2 && fields[0] == "goroutine" {
+			stacks[fields[1]] = strings.TrimSpace(g)
+		}
+	}
+	return stacks
+}
+
+// newGoroutines returns the stacks of goroutines started since before was
+// taken that are running code from outside the standard library, sorted so
+// they read the same from run to run
+// the ones only running the standard library are left out, they are things
+// like net/http keeping a connection open and not the lesson's doing
+func newGoroutines(before map[string]string) []string {
+	var stacks []string
+	for id, g := range goroutines() {
+		if _, old := before[id]; old || !runsUserCode(g) {
+			continue
+		}
+		stacks = append(stacks, g)
+	}
+	sort.Strings(stacks)
+	return stacks
+}
+
+// leakedGoroutines is newGoroutines, after giving them up to grace to exit
+func leakedGoroutines(before map[string]string, grace time.Duration) []string {
+	deadline := time.Now().Add(grace)
+	for {
+		leaked := newGoroutines(before)
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			return leaked
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// runsUserCode reports whether any function in stack comes from package main
+// or a package whose import path starts with a domain, e.g. github.com/...
+func runsUserCode(stack string) bool {
+	for _, line := range strings.Split(stack, "\n")[1:] {
+		if strings.HasPrefix(line, "\t") {
+			continue // file:line of the function above
+		}
+		fn := strings.TrimPrefix(line, "created by ")
+		path := fn
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			path = path[:i] + strings.SplitN(path[i:], ".", 2)[0]
+		} else {
+			path = strings.SplitN(path, ".", 2)[0]
+		}
+		if path == "main" || strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,342 @@
+package golearn
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrWriterClosed is returned by writes to a writer that has been closed
+var ErrWriterClosed = errors.New("write to closed writer")
+
+// the TCPWriter defaults
+const (
+	DefaultDialTimeout  = 5 * time.Second
+	DefaultWriteTimeout = 10 * time.Second
+	DefaultMinBackoff   = 50 * time.Millisecond
+	DefaultMaxBackoff   = 5 * time.Second
+	DefaultMaxRetries   = 5
+)
+
+// NoRetries is the MaxRetries for a TCPWriter that gives up
+// the first time a dial or a write fails
+const NoRetries = -1
+
+// TCPWriter is a Writer to a TCP connection
+// it dials Addr on the first Write, and when the connection breaks
+// (the server went away, a broken pipe, a reset) it dials again,
+// waiting MinBackoff, then twice that, and so on up to MaxBackoff
+// between tries, and gives up after MaxRetries failed tries in a row
+// a write the server doesn't take within WriteTimeout, because it
+// stopped reading, counts as a broken connection too
+// zero fields mean the Default* values above, set MaxRetries to
+// NoRetries to not retry at all
+// a TCPWriter is safe to use from many goroutines at once
+type TCPWriter struct {
+	Addr         string // host:port to dial
+	DialTimeout  time.Duration
+	WriteTimeout time.Duration
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+	MaxRetries   int
+
+	mu sync.Mutex // held for a whole Write, so writes don't interleave
+
+	// connMu guards conn, Close takes only connMu, never mu,
+	// so it can close the connection under a Write stuck on it
+	connMu sync.Mutex
+	conn   *watchedConn
+
+	closeOnce sync.Once
+	done      chan struct{} // closed by Close, cuts a backoff short
+	initOnce  sync.Once
+}
+
+// NewTCPWriter makes a TCPWriter to addr with the default settings
+// it doesn't dial until the first Write
+func NewTCPWriter(addr string) *TCPWriter {
+	return &TCPWriter{Addr: addr}
+}
+
+func (tw *TCPWriter) init() {
+	tw.initOnce.Do(func() { tw.done = make(chan struct{}) })
+}
+
+// Write sends data down the connection, reconnecting if it has to
+// bytes the kernel accepted just before a connection broke may never
+// arrive, TCP doesn't tell us, so a reconnect only resends what the
+// failed write call didn't take
+func (tw *TCPWriter) Write(data []byte) (int, error) {
+	tw.init()
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	written := 0
+	var lastErr error
+	for try := 0; ; try++ {
+		if tw.isClosed() {
+			return written, ErrWriterClosed
+		}
+		if try > tw.maxRetries() {
+			return written, fmt.Errorf("tcp writer %s: giving up after %d tries: %w", tw.Addr, try, lastErr)
+		}
+		if try > 0 && !tw.sleep(tw.backoff(try)) {
+			return written, ErrWriterClosed
+		}
+
+		conn := tw.current()
+		if conn != nil && conn.hungUp() {
+			tw.drop(conn)
+			conn = nil
+		}
+		if conn == nil {
+			c, err := net.DialTimeout("tcp", tw.Addr, tw.dialTimeout())
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if conn = tw.install(watch(c)); conn == nil {
+				return written, ErrWriterClosed
+			}
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(tw.writeTimeout()))
+		n, err := conn.Write(data[written:])
+		written += n
+		if err == nil {
+			return written, nil
+		}
+		lastErr = err
+		tw.drop(conn)
+	}
+}
+
+// Close closes the connection, any Write after it fails with ErrWriterClosed,
+// and so does a Write blocked on the connection when Close is called
+func (tw *TCPWriter) Close() error {
+	tw.init()
+	tw.closeOnce.Do(func() { close(tw.done) })
+	tw.connMu.Lock()
+	conn := tw.conn
+	tw.conn = nil
+	tw.connMu.Unlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+func (tw *TCPWriter) current() *watchedConn {
+	tw.connMu.Lock()
+	defer tw.connMu.Unlock()
+	return tw.conn
+}
+
+// install makes conn the connection to write to, unless tw was closed
+// while it was dialing, then it closes conn and returns nil
+func (tw *TCPWriter) install(conn *watchedConn) *watchedConn {
+	tw.connMu.Lock()
+	defer tw.connMu.Unlock()
+	if tw.isClosed() {
+		conn.Close()
+		return nil
+	}
+	tw.conn = conn
+	return conn
+}
+
+// drop closes conn, which broke, and forgets it
+func (tw *TCPWriter) drop(conn *watchedConn) {
+	tw.connMu.Lock()
+	if tw.conn == conn {
+		tw.conn = nil
+	}
+	tw.connMu.Unlock()
+	conn.Close()
+}
+
+func (tw *TCPWriter) isClosed() bool {
+	select {
+	case <-tw.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep waits d, or less if tw is closed meanwhile,
+// and reports whether tw is still open
+func (tw *TCPWriter) sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-tw.done:
+		return false
+	}
+}
+
+// backoff is how long to wait before the try'th try,
+// doubling from MinBackoff each time up to MaxBackoff
+func (tw *TCPWriter) backoff(try int) time.Duration {
+	d := tw.MinBackoff
+	if d <= 0 {
+		d = DefaultMinBackoff
+	}
+	max := tw.MaxBackoff
+	if max <= 0 {
+		max = DefaultMaxBackoff
+	}
+	for i := 1; i < try && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+func (tw *TCPWriter) maxRetries() int {
+	switch {
+	case tw.MaxRetries < 0:
+		return 0
+	case tw.MaxRetries == 0:
+		return DefaultMaxRetries
+	}
+	return tw.MaxRetries
+}
+
+func (tw *TCPWriter) writeTimeout() time.Duration {
+	if tw.WriteTimeout <= 0 {
+		return DefaultWriteTimeout
+	}
+	return tw.WriteTimeout
+}
+
+func (tw *TCPWriter) dialTimeout() time.Duration {
+	if tw.DialTimeout <= 0 {
+		return DefaultDialTimeout
+	}
+	return tw.DialTimeout
+}
+
+// watchedConn notices when the other end hangs up, which a write alone
+// often doesn't until the write after it, by keeping a read going
+// the writer never reads, so anything the server does send is thrown away
+type watchedConn struct {
+	net.Conn
+	gone chan struct{} // closed once the read fails
+}
+
+func watch(conn net.Conn) *watchedConn {
+	wc := &watchedConn{Conn: conn, gone: make(chan struct{})}
+	go func() {
+		io.Copy(ioutil.Discard, conn) // returns on EOF, a reset or Close
+		close(wc.gone)
+	}()
+	return wc
+}
+
+func (wc *watchedConn) hungUp() bool {
+	select {
+	case <-wc.gone:
+		return true
+	default:
+		return false
+	}
+}
+
+// tcpSink is a server on localhost collecting everything sent to it,
+// so lessons have somewhere to point a TCPWriter
+type tcpSink struct {
+	ln        *net.TCPListener
+	wg        sync.WaitGroup
+	closing   chan struct{} // closed when Close starts draining
+	closeOnce sync.Once
+	mu        sync.Mutex
+	data      bytes.Buffer
+}
+
+// sinkDrain is how long Close keeps accepting once no more connections
+// turn up, a writer that dialed is already queued, so it's quick
+const sinkDrain = 50 * time.Millisecond
+
+func newTCPSink() (*tcpSink, error) {
+	s, err := listenTCPSink()
+	if err != nil {
+		return nil, err
+	}
+	s.start()
+	return s, nil
+}
+
+// listenTCPSink makes a sink that is listening, so it can be dialed,
+// but doesn't accept anything until start
+func listenTCPSink() (*tcpSink, error) {
+	ln, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return nil, err
+	}
+	return &tcpSink{ln: ln, closing: make(chan struct{})}, nil
+}
+
+func (s *tcpSink) start() {
+	s.wg.Add(1)
+	go s.serve()
+}
+
+func (s *tcpSink) Addr() string { return s.ln.Addr().String() }
+
+func (s *tcpSink) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return // Close's drain found nothing more to accept
+		}
+		select {
+		case <-s.closing:
+			s.ln.SetDeadline(time.Now().Add(sinkDrain)) // there may be more queued
+		default:
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			buf := make([]byte, 4096)
+			for {
+				n, err := conn.Read(buf)
+				s.mu.Lock()
+				s.data.Write(buf[:n])
+				s.mu.Unlock()
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// Close stops the server once every connection has hung up,
+// so close the writers first, and returns everything it was sent
+// closing the listener straight away would reset the connections
+// writers dialed that serve hasn't accepted yet, losing what they sent,
+// so it accepts until none have turned up for sinkDrain, and only
+// then closes the listener
+func (s *tcpSink) Close() string {
+	s.closeOnce.Do(func() {
+		close(s.closing)
+		s.ln.SetDeadline(time.Now().Add(sinkDrain))
+		s.wg.Wait()
+		s.ln.Close()
+	})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.String()
+}
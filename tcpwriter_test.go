@@ -0,0 +1,246 @@
+package golearn
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTCPWriter(t *testing.T) {
+	checkLeaks(t)
+	sink, err := newTCPSink()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := NewTCPWriter(sink.Addr())
+
+	// writes from many goroutines arrive whole, never interleaved
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if n, err := tw.Write([]byte("0123456789\n")); n != 11 || err != nil {
+				t.Errorf("Write() = %d, %v, want 11, nil", n, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, expected := sink.Close(), strings.Repeat("0123456789\n", 10); got != expected {
+		t.Errorf("server received %q, want %q", got, expected)
+	}
+
+	if _, err := tw.Write([]byte("too late")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Write() after Close error = %v, want %v", err, ErrWriterClosed)
+	}
+}
+
+// connections dialed but not yet accepted when Close is called still
+// get read, rather than reset by the listener closing under them
+func TestTCPSinkDrains(t *testing.T) {
+	checkLeaks(t)
+	sink, err := listenTCPSink()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const conns = 50
+	for i := 0; i < conns; i++ {
+		c, err := net.Dial("tcp", sink.Addr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Write([]byte("x"))
+		c.Close()
+	}
+	sink.start() // every connection is still waiting to be accepted
+	if got := sink.Close(); got != strings.Repeat("x", conns) {
+		t.Errorf("sink received %q, want %d x's", got, conns)
+	}
+	if got := sink.Close(); got != strings.Repeat("x", conns) {
+		t.Errorf("second Close() = %q", got)
+	}
+}
+
+func TestTCPWriterReconnects(t *testing.T) {
+	checkLeaks(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// the server hangs up after the first message,
+	// then takes a second connection and reads it to the end
+	hungUp := make(chan struct{})
+	second := make(chan string)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			close(hungUp)
+			return
+		}
+		buf := make([]byte, len("first"))
+		c.Read(buf)
+		c.(*net.TCPConn).SetLinger(0) // hang up with a reset, not a goodbye
+		c.Close()
+		close(hungUp)
+
+		c, err = ln.Accept()
+		if err != nil {
+			second <- ""
+			return
+		}
+		b, _ := ioutil.ReadAll(c)
+		c.Close()
+		second <- string(b)
+	}()
+
+	tw := &TCPWriter{Addr: ln.Addr().String(), MinBackoff: time.Millisecond}
+	if _, err := tw.Write([]byte("first")); err != nil {
+		t.Fatal(err)
+	}
+	<-hungUp
+	if _, err := tw.Write([]byte("second")); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+	if got := <-second; got != "second" {
+		t.Errorf("the second connection received %q, want %q", got, "second")
+	}
+}
+
+func TestTCPWriterGivesUp(t *testing.T) {
+	checkLeaks(t)
+	// grab a free port and let it go again, so nothing is listening on it
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	tw := &TCPWriter{Addr: addr, MinBackoff: time.Millisecond, MaxRetries: 3}
+	start := time.Now()
+	_, err = tw.Write([]byte("hello"))
+	var nerr net.Error
+	if err == nil || !errors.As(err, &nerr) {
+		t.Fatalf("Write() error = %v, want the dial error", err)
+	}
+	// 1ms + 2ms + 4ms of backoff between the 4 tries
+	if elapsed := time.Since(start); elapsed < 7*time.Millisecond {
+		t.Errorf("Write() gave up after %v, want it to back off for at least 7ms", elapsed)
+	}
+
+	// NoRetries gives up on the first failure
+	tw = &TCPWriter{Addr: addr, MinBackoff: time.Hour, MaxRetries: NoRetries}
+	if _, err := tw.Write([]byte("hello")); err == nil || !strings.Contains(err.Error(), "after 1 tries") {
+		t.Errorf("Write() with NoRetries error = %v, want it to give up after 1 try", err)
+	}
+
+	// Close cuts a backoff short
+	tw = &TCPWriter{Addr: addr, MinBackoff: time.Hour}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		tw.Close()
+	}()
+	if _, err := tw.Write([]byte("hello")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Write() interrupted by Close error = %v, want %v", err, ErrWriterClosed)
+	}
+}
+
+// stalledServer accepts one connection and never reads from it,
+// so a big enough write fills the socket buffers and blocks
+func stalledServer(t *testing.T) (addr string, stop func()) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	accepted := make(chan struct{})
+	go func() {
+		defer close(accepted)
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		<-release
+		c.Close()
+	}()
+	return ln.Addr().String(), func() {
+		close(release)
+		ln.Close()
+		<-accepted
+	}
+}
+
+// more than the kernel will buffer for a peer that isn't reading
+var stallingWrite = make([]byte, 64<<20)
+
+func TestTCPWriterStalledPeer(t *testing.T) {
+	checkLeaks(t)
+	addr, stop := stalledServer(t)
+	defer stop()
+
+	tw := &TCPWriter{Addr: addr, WriteTimeout: 50 * time.Millisecond, MaxRetries: NoRetries}
+	defer tw.Close()
+	n, err := tw.Write(stallingWrite)
+	var nerr net.Error
+	if !errors.As(err, &nerr) || !nerr.Timeout() {
+		t.Fatalf("Write() to a stalled peer error = %v, want a timeout", err)
+	}
+	if n >= len(stallingWrite) {
+		t.Errorf("Write() to a stalled peer wrote all %d bytes", n)
+	}
+}
+
+func TestTCPWriterCloseUnblocksWrite(t *testing.T) {
+	checkLeaks(t)
+	addr, stop := stalledServer(t)
+	defer stop()
+
+	tw := &TCPWriter{Addr: addr, WriteTimeout: time.Hour}
+	wrote := make(chan error, 1)
+	go func() {
+		_, err := tw.Write(stallingWrite)
+		wrote <- err
+	}()
+	time.Sleep(50 * time.Millisecond) // long enough for the write to fill the buffers
+
+	closed := make(chan error, 1)
+	go func() { closed <- tw.Close() }()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close() waited on the stuck Write")
+	}
+	select {
+	case err := <-wrote:
+		if !errors.Is(err, ErrWriterClosed) {
+			t.Errorf("Write() interrupted by Close error = %v, want %v", err, ErrWriterClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Write() kept blocking after Close")
+	}
+}
+
+func TestTCPWriterBackoff(t *testing.T) {
+	tw := &TCPWriter{MinBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+	var got []time.Duration
+	for try := 1; try <= 5; try++ {
+		got = append(got, tw.backoff(try))
+	}
+	expected := []time.Duration{10, 20, 40, 50, 50}
+	for i := range expected {
+		if got[i] != expected[i]*time.Millisecond {
+			t.Errorf("backoffs = %v, want %v ms", got, expected)
+			break
+		}
+	}
+}
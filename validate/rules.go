@@ -0,0 +1,158 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// rule checks one field's value against the rule's tag value, and returns
+// what is wrong and the kind of problem it is, or a nil kind if nothing is
+// the value may belong to an unexported field, so rules read it with
+// String, Int and friends, never Interface
+type rule func(param string, v reflect.Value) (msg string, kind error)
+
+var rules = map[string]rule{
+	"required": required,
+	"min":      bound("min"),
+	"max":      bound("max"),
+	"len":      bound("len"),
+	"oneof":    oneOf,
+	"regexp":   matches,
+}
+
+func required(param string, v reflect.Value) (string, error) {
+	on, err := strconv.ParseBool(param)
+	if err != nil {
+		return "want true or false", ErrBadRule
+	}
+	if on && v.IsZero() {
+		return "is required", ErrInvalid
+	}
+	return "", nil
+}
+
+// bound makes the min, max and len rules, which only differ in how they
+// compare, a nil pointer is skipped, that's required's job
+func bound(name string) rule {
+	return func(param string, v reflect.Value) (string, error) {
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return "want a number", ErrBadRule
+		}
+		v, ok := deref(v)
+		if !ok {
+			return "", nil
+		}
+		got, what, ok := measure(v)
+		if !ok {
+			return fmt.Sprintf("can't measure a %s", v.Kind()), ErrBadRule
+		}
+		switch {
+		case name == "min" && got < limit:
+			return fmt.Sprintf("%s%s is less than %s", what, num(got), param), ErrInvalid
+		case name == "max" && got > limit:
+			return fmt.Sprintf("%s%s is more than %s", what, num(got), param), ErrInvalid
+		case name == "len" && got != limit:
+			return fmt.Sprintf("%s%s is not %s", what, num(got), param), ErrInvalid
+		}
+		return "", nil
+	}
+}
+
+// measure is what min, max and len compare, a number's value or
+// the length of anything else, what says which it was for messages
+func measure(v reflect.Value) (n float64, what string, ok bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), "length ", true
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return float64(v.Len()), "length ", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "", true
+	}
+	return 0, "", false
+}
+
+func oneOf(param string, v reflect.Value) (string, error) {
+	v, ok := deref(v)
+	if !ok {
+		return "", nil
+	}
+	var got string
+	switch v.Kind() {
+	case reflect.String:
+		got = v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		n, _, _ := measure(v)
+		got = num(n)
+	default:
+		return fmt.Sprintf("can't compare a %s", v.Kind()), ErrBadRule
+	}
+	choices := strings.Fields(param)
+	for _, c := range choices {
+		if c == got {
+			return "", nil
+		}
+	}
+	return fmt.Sprintf("%q is not one of %v", got, choices), ErrInvalid
+}
+
+func matches(param string, v reflect.Value) (string, error) {
+	re, err := compile(param)
+	if err != nil {
+		return err.Error(), ErrBadRule
+	}
+	v, ok := deref(v)
+	if !ok {
+		return "", nil
+	}
+	if v.Kind() != reflect.String {
+		return fmt.Sprintf("can't match a %s", v.Kind()), ErrBadRule
+	}
+	if !re.MatchString(v.String()) {
+		return fmt.Sprintf("%q doesn't match", v.String()), ErrInvalid
+	}
+	return "", nil
+}
+
+// compiled keeps every regexp rule seen so far, the same tags
+// come round every time a type is validated
+var compiled sync.Map // string -> *regexp.Regexp
+
+func compile(expr string) (*regexp.Regexp, error) {
+	if re, ok := compiled.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	compiled.Store(expr, re)
+	return re, nil
+}
+
+// deref follows pointers to the value underneath, ok is false for a nil one
+func deref(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+func num(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,299 @@
+// Package validate checks struct fields against rules in their tags,
+// the validation framework MapsAndStructs says reflect makes possible
+//
+// each rule is a tag key of its own, the way golearn's Animal is tagged:
+//
+//	type Animal struct {
+//		Name   string `required:"true" max:"100"`
+//		Origin string `oneof:"Africa Asia Australia Europe"`
+//	}
+//
+// the rules are
+//
+//	required:"true"   not the zero value: a non-empty string, slice or map,
+//	                  a non-zero number, a non-nil pointer
+//	min:"n" max:"n"   at least / at most n, for numbers the value itself,
+//	                  for strings (in runes), slices, arrays and maps the length
+//	len:"n"           exactly n long
+//	oneof:"a b c"     one of the space separated values, strings and numbers
+//	regexp:"expr"     a string matching expr
+//
+// embedded structs, struct fields, pointers to structs and slices of
+// structs are checked too, with paths like Animal.Name or Flock[2].Name
+// a tag key that isn't a rule, and isn't one of the keys other packages
+// use (json and friends, see Validator.Ignore), is reported rather than
+// quietly ignored, so a typo like reqired doesn't switch a rule off
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// the kinds of problem Struct reports, match them with errors.Is
+var (
+	// ErrInvalid means a field's value breaks one of its rules
+	ErrInvalid = errors.New("invalid value")
+	// ErrUnknownRule means a tag key is neither a rule nor ignored
+	ErrUnknownRule = errors.New("unknown rule")
+	// ErrBadRule means a rule can't be applied, e.g. max:"lots"
+	// or regexp on an int
+	ErrBadRule = errors.New("bad rule")
+)
+
+// FieldError is one problem with one field
+// errors.Is matches it against its Kind, one of the Err* values above
+type FieldError struct {
+	Path  string // where the field is, e.g. "Animal.Name"
+	Rule  string // the tag key, e.g. "max"
+	Param string // the tag value, e.g. "100"
+	Kind  error  // ErrInvalid, ErrUnknownRule or ErrBadRule
+	Msg   string // what is wrong, e.g. "length 120 is more than 100"
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s:%q: %s", e.Path, e.Rule, e.Param, e.Msg)
+}
+
+// Is reports whether target is this error's Kind
+func (e *FieldError) Is(target error) bool { return target == e.Kind }
+
+// Errors is every problem Struct found, in field order
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the errors is target
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultIgnore is the tag keys other packages read,
+// which Struct leaves alone rather than calling them unknown rules
+var DefaultIgnore = []string{"json", "xml", "yaml", "toml", "db", "bson", "protobuf", "mapstructure", "form", "csv", "env"}
+
+// Validator checks structs against their tags
+type Validator struct {
+	// Ignore is the tag keys to leave alone, DefaultIgnore when nil
+	Ignore []string
+}
+
+// Struct checks v, a struct or pointer to one, with the default Validator
+func Struct(v interface{}) error {
+	return (&Validator{}).Struct(v)
+}
+
+// Struct checks v, a struct or pointer to one, against its tags and returns
+// Errors holding every problem it found, or nil if there were none
+// it follows pointers, interfaces and slices, each one once, so cyclic
+// data like a doubly linked list is fine
+func (val *Validator) Struct(v interface{}) error {
+	w := walker{ignore: make(map[string]bool), seen: make(map[visit]bool)}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		w.first(rv)
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: %T is not a struct", v)
+	}
+
+	ignore := val.Ignore
+	if ignore == nil {
+		ignore = DefaultIgnore
+	}
+	for _, key := range ignore {
+		w.ignore[key] = true
+	}
+	w.walkStruct("", rv)
+	if len(w.errs) == 0 {
+		return nil
+	}
+	return w.errs
+}
+
+// walker carries what Struct has found so far down into nested structs
+type walker struct {
+	ignore map[string]bool
+	errs   Errors
+	seen   map[visit]bool
+}
+
+// visit is a pointer or slice walker has been into, the type is part of it
+// since a struct and its first field have the same address, and the length
+// since s[:1] and s have the same address too
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// first reports whether this is the first time w has come across
+// the pointer or slice v, so data that points back at itself,
+// like a.Next = a or a doubly linked list, is only walked once
+func (w *walker) first(v reflect.Value) bool {
+	k := visit{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		k.len = v.Len()
+	}
+	if w.seen[k] {
+		return false
+	}
+	w.seen[k] = true
+	return true
+}
+
+func (w *walker) walkStruct(path string, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fpath := f.Name
+		if path != "" {
+			fpath = path + "." + f.Name
+		}
+		fv := v.Field(i)
+		w.checkField(fpath, f.Tag, fv)
+		w.walkValue(fpath, fv)
+	}
+}
+
+// walkValue looks inside v for more structs to check
+func (w *walker) walkValue(path string, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		w.walkStruct(path, v)
+	case reflect.Ptr:
+		if !v.IsNil() && w.first(v) {
+			w.walkValue(path, v.Elem())
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			w.walkValue(path, v.Elem())
+		}
+	case reflect.Slice:
+		if v.Len() == 0 || !w.first(v) {
+			return
+		}
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			w.walkValue(fmt.Sprintf("%s[%d]", path, i), v.Index(i))
+		}
+	}
+}
+
+func (w *walker) checkField(path string, tag reflect.StructTag, v reflect.Value) {
+	for _, kv := range parseTag(tag) {
+		r, ok := rules[kv.key]
+		if !ok {
+			if w.ignore[kv.key] {
+				continue
+			}
+			msg := "not a rule"
+			if guess := closestRule(kv.key); guess != "" {
+				msg = fmt.Sprintf("not a rule, did you mean %q?", guess)
+			}
+			w.add(path, kv, ErrUnknownRule, msg)
+			continue
+		}
+		if msg, kind := r(kv.value, v); kind != nil {
+			w.add(path, kv, kind, msg)
+		}
+	}
+}
+
+func (w *walker) add(path string, kv tagPair, kind error, msg string) {
+	w.errs = append(w.errs, &FieldError{Path: path, Rule: kv.key, Param: kv.value, Kind: kind, Msg: msg})
+}
+
+type tagPair struct{ key, value string }
+
+// parseTag splits a struct tag into its key:"value" pairs, in order
+// reflect.StructTag can only look keys up, not list them,
+// so this follows the same rules StructTag.Lookup does
+func parseTag(tag reflect.StructTag) []tagPair {
+	var pairs []tagPair
+	s := string(tag)
+	for s != "" {
+		s = strings.TrimLeft(s, " ")
+		i := 0
+		for i < len(s) && s[i] > ' ' && s[i] != ':' && s[i] != '"' && s[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(s) || s[i] != ':' || s[i+1] != '"' {
+			break
+		}
+		key := s[:i]
+		s = s[i+1:]
+
+		i = 1
+		for i < len(s) && s[i] != '"' {
+			if s[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		value, _ := tag.Lookup(key)
+		pairs = append(pairs, tagPair{key, value})
+		s = s[i+1:]
+	}
+	return pairs
+}
+
+// closestRule is the rule name within two edits of key, if there is one
+func closestRule(key string) string {
+	best, bestDist := "", 3
+	for name := range rules {
+		if d := editDistance(key, name); d < bestDist || (d == bestDist && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,224 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type animal struct {
+	Name   string `required:"true" max:"10"`
+	Origin string `json:"origin" oneof:"Africa Asia Australia"`
+}
+
+type bird struct {
+	animal
+	SpeedKPH float32  `min:"0" max:"400"`
+	Tags     []string `len:"2"`
+	Code     string   `regexp:"^[A-Z]{3}$"`
+	Mate     *bird
+	Flock    []bird
+	Legs     int  `oneof:"0 2 4"`
+	Ringed   *int `required:"true"`
+	note     string
+}
+
+func validBird() bird {
+	ring := 7
+	return bird{
+		animal:   animal{Name: "Emu", Origin: "Australia"},
+		SpeedKPH: 48,
+		Tags:     []string{"big", "fast"},
+		Code:     "EMU",
+		Legs:     2,
+		Ringed:   &ring,
+	}
+}
+
+// paths is each error's Path and Rule, in order
+func paths(err error) []string {
+	var errs Errors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+	var out []string
+	for _, e := range errs {
+		out = append(out, e.Path+" "+e.Rule)
+	}
+	return out
+}
+
+func TestStruct(t *testing.T) {
+	if err := Struct(validBird()); err != nil {
+		t.Fatalf("Struct(valid bird) = %v", err)
+	}
+
+	b := validBird()
+	b.Name = "A very long name"
+	b.Origin = "Mars"
+	b.SpeedKPH = 500
+	b.Tags = []string{"one"}
+	b.Code = "emu"
+	b.Legs = 3
+	b.Ringed = nil
+	mate := validBird()
+	mate.Name = ""
+	b.Mate = &mate
+	flockmate := validBird()
+	flockmate.SpeedKPH = -1
+	b.Flock = []bird{validBird(), flockmate}
+
+	err := Struct(&b)
+	expected := []string{
+		"animal.Name max",
+		"animal.Origin oneof",
+		"SpeedKPH max",
+		"Tags len",
+		"Code regexp",
+		"Mate.animal.Name required",
+		"Flock[1].SpeedKPH min",
+		"Legs oneof",
+		"Ringed required",
+	}
+	if got := paths(err); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Struct() found\n%v\nwant\n%v", got, expected)
+	}
+	if !errors.Is(err, ErrInvalid) || errors.Is(err, ErrUnknownRule) || errors.Is(err, ErrBadRule) {
+		t.Errorf("Struct() = %v, want only ErrInvalid", err)
+	}
+}
+
+func TestStructMessages(t *testing.T) {
+	v := struct {
+		Name  string   `max:"3"`
+		Count int      `min:"5"`
+		Kind  string   `oneof:"a b"`
+		Items []string `len:"1"`
+	}{Name: "héllo", Count: 2, Kind: "c"}
+	expected := `Name: max:"3": length 5 is more than 3; ` +
+		`Count: min:"5": 2 is less than 5; ` +
+		`Kind: oneof:"a b": "c" is not one of [a b]; ` +
+		`Items: len:"1": length 0 is not 1`
+	if err := Struct(v); err == nil || err.Error() != expected {
+		t.Errorf("Struct() = %v\nwant %s", err, expected)
+	}
+}
+
+func TestStructUnknownRules(t *testing.T) {
+	v := struct {
+		A string `reqired:"true"`
+		B string `mx:"3"`
+		C string `frobnicate:"yes"`
+		D string `json:"d" yaml:"d"`
+	}{}
+	err := Struct(v)
+	if !errors.Is(err, ErrUnknownRule) {
+		t.Fatalf("Struct() = %v, want ErrUnknownRule", err)
+	}
+	for _, want := range []string{
+		`A: reqired:"true": not a rule, did you mean "required"?`,
+		`B: mx:"3": not a rule, did you mean "max"?`,
+		`C: frobnicate:"yes": not a rule`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Struct() = %v, want it to say %s", err, want)
+		}
+	}
+	if got := paths(err); len(got) != 3 {
+		t.Errorf("Struct() found %v, want json and yaml ignored", got)
+	}
+
+	strict := Validator{Ignore: []string{}}
+	if got := paths(strict.Struct(v)); len(got) != 5 {
+		t.Errorf("Validator with nothing ignored found %v, want json and yaml too", got)
+	}
+}
+
+func TestStructBadRules(t *testing.T) {
+	for _, v := range []interface{}{
+		struct {
+			A string `max:"lots"`
+		}{},
+		struct {
+			A bool `min:"1"`
+		}{},
+		struct {
+			A int `regexp:"^1$"`
+		}{},
+		struct {
+			A string `regexp:"("`
+		}{},
+		struct {
+			A string `required:"yes please"`
+		}{},
+		struct {
+			A []int `oneof:"1 2"`
+		}{},
+	} {
+		if err := Struct(v); !errors.Is(err, ErrBadRule) {
+			t.Errorf("Struct(%#v) = %v, want ErrBadRule", v, err)
+		}
+	}
+}
+
+type node struct {
+	Name       string `required:"true"`
+	Next, Prev *node
+	Children   []node
+}
+
+type loop []loop
+
+type holder struct {
+	Loop loop
+	Any  interface{}
+}
+
+// data pointing back at itself is checked once, not forever
+func TestStructCycles(t *testing.T) {
+	self := &node{}
+	self.Next, self.Prev = self, self
+	if got := paths(Struct(self)); !reflect.DeepEqual(got, []string{"Name required"}) {
+		t.Errorf("Struct(self-linked node) = %v", got)
+	}
+
+	// a doubly linked list a <-> b <-> c, with b missing its name
+	a, b, c := &node{Name: "a"}, &node{}, &node{Name: "c"}
+	a.Next, b.Prev, b.Next, c.Prev = b, a, c, b
+	if got := paths(Struct(c)); !reflect.DeepEqual(got, []string{"Prev.Name required"}) {
+		t.Errorf("Struct(doubly linked list) = %v", got)
+	}
+
+	// a slice holding itself, and a struct reached through an interface
+	l := loop{nil}
+	l[0] = l
+	parent := &node{Name: "parent", Children: []node{{}}}
+	parent.Children[0].Prev = parent
+	if got := paths(Struct(holder{Loop: l, Any: parent})); !reflect.DeepEqual(got, []string{"Any.Children[0].Name required"}) {
+		t.Errorf("Struct(cyclic slice and back-pointer) = %v", got)
+	}
+
+	// a prefix of a slice doesn't hide the rest of it
+	kids := []node{{Name: "x"}, {}}
+	pair := struct{ A, B []node }{kids[:1], kids}
+	if got := paths(Struct(pair)); !reflect.DeepEqual(got, []string{"B[1].Name required"}) {
+		t.Errorf("Struct(overlapping slices) = %v", got)
+	}
+}
+
+func TestStructNotAStruct(t *testing.T) {
+	for _, v := range []interface{}{nil, 3, "bird", (*bird)(nil)} {
+		if err := Struct(v); err == nil || errors.Is(err, ErrInvalid) {
+			t.Errorf("Struct(%#v) = %v, want a not-a-struct error", v, err)
+		}
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	tag := reflect.StructTag(`required:"true" json:"name,omitempty"  regexp:"^a\"b$"`)
+	expected := []tagPair{{"required", "true"}, {"json", "name,omitempty"}, {"regexp", `^a"b$`}}
+	if got := parseTag(tag); !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseTag(%s) = %v, want %v", tag, got, expected)
+	}
+}